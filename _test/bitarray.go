@@ -25,7 +25,7 @@ func (ba *BitArray) AddVar(bits uint64, sigBits int) {
 	}
 
 	for i := 0; i < sigBits; i++ {
-		n := (bits >> uint(64-i)) & 1
+		n := (bits >> uint(63-i)) & 1
 		ba.Add(n != 0)
 	}
 }
@@ -78,6 +78,19 @@ func (ba *BitArray) Equals(barr *BitArray) bool {
 	return true
 }
 
+// Bytes returns the bit array packed into a slice of bytes, starting with
+// the left-most bit of the first byte. The last byte is padded with zero bits
+// if needed.
+func (ba *BitArray) Bytes() []byte {
+	buf := make([]byte, (len(ba.buf)+7)/8)
+	for i, b := range ba.buf {
+		if b {
+			buf[i/8] |= 1 << uint(7-i%8)
+		}
+	}
+	return buf
+}
+
 // String returns a string representation of the bit array.
 func (ba *BitArray) String() string {
 	buf := bytes.Buffer{}
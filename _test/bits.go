@@ -0,0 +1,29 @@
+package _test
+
+import "fmt"
+
+// PackBits converts a string of '0' and '1' characters into a slice of bytes,
+// starting with the left-most bit of the first byte. Spaces are ignored so
+// fields can be separated for readability. The last byte is padded with zero
+// bits if needed.
+func PackBits(s string) []byte {
+	var buf []byte
+	var count int
+	for _, c := range s {
+		switch c {
+		case ' ':
+			continue
+		case '0', '1':
+		default:
+			panic(fmt.Errorf("invalid bit character %q", c))
+		}
+		if count%8 == 0 {
+			buf = append(buf, 0)
+		}
+		if c == '1' {
+			buf[len(buf)-1] |= 1 << uint(7-count%8)
+		}
+		count++
+	}
+	return buf
+}
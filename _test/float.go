@@ -0,0 +1,15 @@
+package _test
+
+import "math"
+
+// BFloat16Bits returns the bfloat16 bits for `f`, rounding the discarded low
+// 16 bits to nearest even. NaNs remain NaNs.
+func BFloat16Bits(f float32) uint16 {
+	b := math.Float32bits(f)
+	if f != f {
+		// keep a quiet NaN, even if the payload was only in the low bits
+		return uint16(b>>16) | 0x0040
+	}
+	b += 0x7FFF + (b>>16)&1
+	return uint16(b >> 16)
+}
@@ -0,0 +1,28 @@
+package _test
+
+// Hamming74Encode returns the Hamming(7,4) codeword for the low 4 bits of
+// `nibble`, right aligned, in position order p1 p2 d1 p3 d2 d3 d4.
+func Hamming74Encode(nibble byte) byte {
+	d1 := nibble >> 3 & 1
+	d2 := nibble >> 2 & 1
+	d3 := nibble >> 1 & 1
+	d4 := nibble & 1
+
+	p1 := d1 ^ d2 ^ d4
+	p2 := d1 ^ d3 ^ d4
+	p3 := d2 ^ d3 ^ d4
+
+	return p1<<6 | p2<<5 | d1<<4 | p3<<3 | d2<<2 | d3<<1 | d4
+}
+
+// Hamming84Encode returns the extended Hamming(8,4) codeword for the low
+// 4 bits of `nibble`, which is the Hamming(7,4) codeword followed by an
+// overall even parity bit.
+func Hamming84Encode(nibble byte) byte {
+	cw := Hamming74Encode(nibble)
+	var parity byte
+	for b := cw; b != 0; b >>= 1 {
+		parity ^= b & 1
+	}
+	return cw<<1 | parity
+}
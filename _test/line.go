@@ -0,0 +1,38 @@
+package _test
+
+// ManchesterEncode returns the Manchester coding of `data`, two chips per bit
+// starting with the left-most bit. When `ieee` is true a 1 is coded as 01 and
+// a 0 as 10, otherwise the G.E. Thomas convention (1 as 10, 0 as 01) is used.
+func ManchesterEncode(data []byte, ieee bool) []byte {
+	ba := &BitArray{}
+	for _, b := range data {
+		for i := 7; i >= 0; i-- {
+			bit := (b>>uint(i))&1 == 1
+			if ieee {
+				ba.Add(!bit)
+				ba.Add(bit)
+			} else {
+				ba.Add(bit)
+				ba.Add(!bit)
+			}
+		}
+	}
+	return ba.Bytes()
+}
+
+// NRZIEncode returns the NRZI coding of `data`, one chip per bit starting with
+// the left-most bit and an initial line level of `level`. A 1 is coded as no
+// transition and a 0 as a transition, or the opposite if `invert` is true.
+func NRZIEncode(data []byte, invert bool, level bool) []byte {
+	ba := &BitArray{}
+	for _, b := range data {
+		for i := 7; i >= 0; i-- {
+			bit := (b>>uint(i))&1 == 1
+			if bit == invert {
+				level = !level
+			}
+			ba.Add(level)
+		}
+	}
+	return ba.Bytes()
+}
@@ -0,0 +1,72 @@
+package nibs
+
+import "math"
+
+// NibbleADPCM4 reads a 4-bit ADPCM code, as used by IMA and similar ADPCM
+// audio formats, and returns it as a two's complement signed nibble in the
+// range -8 to 7. Codes are read in stream order, so for formats that store the
+// first sample in the low nibble of each byte, the pair must be swapped by the
+// caller.
+//
+// See `Nibble` method for details.
+func (n *Nibs) NibbleADPCM4() (int, error) {
+	v, err := n.Nibble(4)
+	if err != nil {
+		return 0, err
+	}
+	return int(signExtend(v, 4)), nil
+}
+
+// ReadADPCM4 fills `dst` with signed 4-bit ADPCM codes, as returned by
+// `NibbleADPCM4`, and returns the number of codes read. Up to 16 codes are
+// read at a time.
+//
+// If fewer than len(`dst`) codes remain, the codes read are returned along
+// with io.EOF if none were read, or io.ErrUnexpectedEOF otherwise.
+func (n *Nibs) ReadADPCM4(dst []int) (int, error) {
+	c, err := n.readPacked(len(dst), 4, func(i int, v uint64) { dst[i] = int(signExtend(v, 4)) })
+	if err != nil && c > 0 {
+		err = unexpected(err)
+	}
+	return c, err
+}
+
+// dpcmHistory is the number of samples passed to a `NibbleDPCM` predictor,
+// enough for the highest order linear predictors of FLAC.
+const dpcmHistory = 32
+
+// NibbleDPCM reads a two's complement residual of `bits` bits and returns it
+// added to the estimate made by `predictor`, reconstructing a sample of
+// differential PCM audio as coded by FLAC and Shorten. The predictor is passed
+// the samples returned before, oldest first, up to the last 32, and may keep
+// the slice only until it returns. The history is empty for the first call
+// since the Nibs was created, `Reset` or `Rewind`, so the predictor returns
+// the warm-up estimate, typically 0, for samples it has too little history for.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned. ErrOverflow is returned if the sample does
+// not fit in an int64, and the history is unchanged.
+func (n *Nibs) NibbleDPCM(bits int, predictor func(history []int64) int64) (int64, error) {
+	v, err := n.Nibble(bits)
+	if err != nil {
+		return 0, err
+	}
+	residual := signExtend(v, bits)
+
+	history := n.dpcm
+	if len(history) > dpcmHistory {
+		history = history[len(history)-dpcmHistory:]
+	}
+	estimate := predictor(history)
+	if residual > 0 && estimate > math.MaxInt64-residual || residual < 0 && estimate < math.MinInt64-residual {
+		return 0, n.wrap(ErrOverflow)
+	}
+	sample := estimate + residual
+
+	if len(n.dpcm) == 2*dpcmHistory {
+		// keep the history from growing by sliding it to the front
+		n.dpcm = n.dpcm[:copy(n.dpcm, n.dpcm[dpcmHistory:])]
+	}
+	n.dpcm = append(n.dpcm, sample)
+	return sample, nil
+}
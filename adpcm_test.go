@@ -0,0 +1,133 @@
+package nibs_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestNibbleADPCM4(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0x01, 0x78, 0x9F}))
+	for _, expected := range []int{0, 1, 7, -8, -7, -1} {
+		if v, err := nib.NibbleADPCM4(); err != nil || v != expected {
+			t.Errorf("expected %d, got %d and error `%v`", expected, v, err)
+		}
+	}
+	if _, err := nib.NibbleADPCM4(); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestReadADPCM4(t *testing.T) {
+	// every code from 0 to 15, three times over
+	b := bytes.Repeat([]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xAB, 0xCD, 0xEF}, 3)
+	nib := nibs.New(bytes.NewReader(b))
+
+	block := make([]int, 40)
+	c, err := nib.ReadADPCM4(block)
+	if err != nil || c != 40 {
+		t.Fatalf("expected 40 codes, got %d and error `%v`", c, err)
+	}
+	for i, v := range block {
+		expected := i % 16
+		if expected > 7 {
+			expected -= 16
+		}
+		if v != expected {
+			t.Errorf("code %d: expected %d, got %d", i, expected, v)
+		}
+	}
+
+	// 8 codes left
+	c, err = nib.ReadADPCM4(block)
+	if err != io.ErrUnexpectedEOF || c != 8 {
+		t.Errorf("expected 8 codes and `io.ErrUnexpectedEOF`, got %d and `%v`", c, err)
+	}
+	if block[7] != -1 {
+		t.Errorf("expected last code -1, got %d", block[7])
+	}
+	if c, err := nib.ReadADPCM4(block); err != io.EOF || c != 0 {
+		t.Errorf("expected 0 codes and `io.EOF`, got %d and `%v`", c, err)
+	}
+}
+
+func TestNibbleDPCM(t *testing.T) {
+	// first order: each sample is predicted to equal the one before
+	previous := func(history []int64) int64 {
+		if len(history) == 0 {
+			return 0
+		}
+		return history[len(history)-1]
+	}
+	samples := []int64{100, 103, 101, 96, 96, 110}
+	ba := &BitArray{}
+	var last int64
+	for _, s := range samples {
+		ba.AddVar(uint64(s-last)<<56, 8)
+		last = s
+	}
+	nib := nibs.New(bytes.NewReader(ba.Bytes()))
+
+	for i, expected := range samples {
+		v, err := nib.NibbleDPCM(8, previous)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v != expected {
+			t.Errorf("sample %d: expected %d, got %d", i, expected, v)
+		}
+	}
+	if _, err := nib.NibbleDPCM(8, previous); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+	if _, err := nib.NibbleDPCM(65, previous); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+
+	// the history restarts after a rewind
+	if err := nib.Rewind(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, err := nib.NibbleDPCM(8, previous); err != nil || v != samples[0] {
+		t.Errorf("expected %d, got %d and error `%v`", samples[0], v, err)
+	}
+}
+
+func TestNibbleDPCMHistory(t *testing.T) {
+	// second order prediction of a ramp leaves only zero residuals, given
+	// the first two samples
+	const count = 200
+	ba := &BitArray{}
+	ba.AddVar(5<<60, 4)
+	ba.AddVar(2<<60, 4)
+	for i := 2; i < count; i++ {
+		ba.AddVar(0, 4)
+	}
+	nib := nibs.New(bytes.NewReader(ba.Bytes()))
+
+	linear := func(history []int64) int64 {
+		if len(history) > 32 {
+			t.Fatalf("expected at most 32 samples of history, got %d", len(history))
+		}
+		switch len(history) {
+		case 0:
+			return 0
+		case 1:
+			return history[0]
+		}
+		return 2*history[len(history)-1] - history[len(history)-2]
+	}
+	for i := 0; i < count; i++ {
+		v, err := nib.NibbleDPCM(4, linear)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := int64(5 + 2*i); v != expected {
+			t.Fatalf("sample %d: expected %d, got %d", i, expected, v)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package nibs
+
+import "fmt"
+
+// ErrMisaligned is the error used when a field expected to start on a
+// boundary does not.
+var ErrMisaligned = newSentinel("field is misaligned")
+
+// ErrNonZeroPadding is the error used when padding read from the stream has
+// bits set. See `WithLenientPadding`.
+var ErrNonZeroPadding = newSentinel("padding is not zero")
+
+// Align skips bits until the position within the stream is a multiple of 8,
+// and returns the number of bits skipped. See `AlignTo`.
+func (n *Nibs) Align() (int, error) {
+	return n.AlignTo(8)
+}
+
+// AlignTo skips bits until the position within the stream is a multiple of
+// `unitBits`, and returns the number of bits skipped. For example, `AlignTo(4)`
+// moves to the next nibble boundary. Nothing is skipped if the position is
+// already aligned. The position is counted from the start of the stream,
+// regardless of `ResetCounters`.
+//
+// `unitBits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned. io.EOF is returned if the stream ends before
+// the boundary, in which case no bits are skipped.
+func (n *Nibs) AlignTo(unitBits int) (int, error) {
+	if unitBits < 1 || unitBits > 64 {
+		return 0, n.sizeError("AlignTo", unitBits, 64)
+	}
+	skip := int((int64(unitBits) - n.offset()%int64(unitBits)) % int64(unitBits))
+	if skip == 0 {
+		return 0, nil
+	}
+	if err := n.Skip(int64(skip)); err != nil {
+		return 0, err
+	}
+	return skip, nil
+}
+
+// NibbleAlignedField reads `bits` number of bits from the byte stream, like
+// `Nibble`, after checking that the position within the stream is a multiple
+// of `unitBits`. This catches a parser that has drifted from the layout it
+// expects, rather than reading from the wrong offset. An error wrapping
+// ErrMisaligned, and stating the position, is returned if the position is not
+// aligned, and no bits are consumed. The position is counted from the start of
+// the stream, regardless of `ResetCounters`.
+//
+// `unitBits` and `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+func (n *Nibs) NibbleAlignedField(unitBits, bits int) (uint64, error) {
+	if unitBits < 1 || unitBits > 64 {
+		return 0, n.sizeError("NibbleAlignedField", unitBits, 64)
+	}
+	if off := n.offset(); off%int64(unitBits) != 0 {
+		return 0, n.wrap(fmt.Errorf("%w: offset %d is not a multiple of %d bits", ErrMisaligned, off, unitBits))
+	}
+	return n.Nibble(bits)
+}
+
+// ReadAlignedStruct aligns to the next byte boundary, as `Align` does, then
+// reads len(p) bytes into `p`. It returns the absolute bit offset at which the
+// bytes began, for correlating a parsed structure with its position in the
+// stream. The offset is counted from the start of the stream, regardless of
+// `ResetCounters`.
+//
+// io.EOF is returned if the stream ends before the first byte of `p`, and
+// io.ErrUnexpectedEOF if it ends part way through. The bits skipped to align
+// are consumed in either case.
+func (n *Nibs) ReadAlignedStruct(p []byte) (startBit int64, err error) {
+	defer n.field(&err)()
+
+	if _, err := n.Align(); err != nil {
+		return n.offset(), err
+	}
+	startBit = n.offset()
+
+	for i := 0; i < len(p); {
+		size := len(p) - i
+		if size > 8 {
+			size = 8
+		}
+		v, err := n.Nibble(size * 8)
+		if err != nil {
+			if i > 0 {
+				err = unexpected(err)
+			}
+			return startBit, err
+		}
+		for j := size - 1; j >= 0; j-- {
+			p[i+j] = byte(v)
+			v >>= 8
+		}
+		i += size
+	}
+	return startBit, nil
+}
+
+// NibblePaddedRecord reads a record made of a `lengthBits` length in bytes,
+// that many bytes of data, then padding up to the next multiple of
+// `alignBytes` bytes, as used by XDR and RIFF. The data is returned. The
+// boundary is counted from the start of the stream, regardless of
+// `ResetCounters`.
+//
+// An error wrapping ErrNonZeroPadding is returned if the padding has any bits
+// set, unless the option `WithLenientPadding` is used. The record is consumed
+// in that case.
+//
+// `lengthBits` must be in the range 1 to 64 inclusive and `alignBytes` must be
+// at least 1, otherwise nibs.ErrNibbleSize is returned. A length that would
+// allocate more than allowed by `WithMaxAlloc` returns an error wrapping
+// ErrAllocLimit. io.EOF is returned if the stream is exhausted before the
+// length, and io.ErrUnexpectedEOF if it ends part way through the record,
+// including when the length is larger than the stream can hold.
+func (n *Nibs) NibblePaddedRecord(lengthBits, alignBytes int) (data []byte, err error) {
+	defer n.field(&err)()
+
+	if alignBytes < 1 {
+		return nil, n.misuseError("NibblePaddedRecord", fmt.Sprintf("alignment of %d bytes; must be at least 1", alignBytes))
+	}
+	length, err := n.Nibble(lengthBits)
+	if err != nil {
+		return nil, err
+	}
+	data, err = n.nibbleBytes(length)
+	if err != nil {
+		return nil, err
+	}
+
+	unit := int64(alignBytes) * 8
+	for pad := (unit - n.offset()%unit) % unit; pad > 0; {
+		size := 64
+		if pad < int64(size) {
+			size = int(pad)
+		}
+		v, err := n.Nibble(size)
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		if v != 0 && !n.lenientPadding {
+			return nil, n.wrap(fmt.Errorf("%w: %#x at offset %d", ErrNonZeroPadding, v, n.offset()-int64(size)))
+		}
+		pad -= int64(size)
+	}
+	return data, nil
+}
@@ -0,0 +1,228 @@
+package nibs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestAlignTo(t *testing.T) {
+	tests := []struct {
+		lead     int
+		unit     int
+		expected int
+	}{
+		{lead: 0, unit: 4, expected: 0},
+		{lead: 1, unit: 4, expected: 3},
+		{lead: 3, unit: 4, expected: 1},
+		{lead: 4, unit: 4, expected: 0},
+		{lead: 13, unit: 4, expected: 3},
+		{lead: 0, unit: 16, expected: 0},
+		{lead: 1, unit: 16, expected: 15},
+		{lead: 9, unit: 16, expected: 7},
+		{lead: 16, unit: 16, expected: 0},
+		{lead: 17, unit: 16, expected: 15},
+		{lead: 5, unit: 1, expected: 0},
+		{lead: 5, unit: 64, expected: 59},
+	}
+
+	b := make([]byte, 32)
+	for i := range b {
+		b[i] = byte(i)
+	}
+
+	for _, tt := range tests {
+		nib := nibs.New(bytes.NewReader(b))
+		if tt.lead > 0 {
+			if _, err := nib.Nibble(tt.lead); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		skipped, err := nib.AlignTo(tt.unit)
+		if err != nil || skipped != tt.expected {
+			t.Errorf("expected %d bits skipped from %d to unit %d, got %d and error `%v`",
+				tt.expected, tt.lead, tt.unit, skipped, err)
+		}
+		if pos := nib.BitsRead(); pos%int64(tt.unit) != 0 {
+			t.Errorf("expected position aligned to %d, got %d", tt.unit, pos)
+		}
+	}
+}
+
+func TestAlign(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xFF, 0x5A}))
+	if _, err := nib.Nibble(3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if skipped, err := nib.Align(); err != nil || skipped != 5 {
+		t.Errorf("expected 5 bits skipped, got %d and error `%v`", skipped, err)
+	}
+	if v, err := nib.Nibble(8); err != nil || v != 0x5A {
+		t.Errorf("expected 0x5A, got %#x and error `%v`", v, err)
+	}
+}
+
+func TestAlignToErrors(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xFF}))
+	if _, err := nib.AlignTo(0); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, err := nib.AlignTo(65); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+
+	if _, err := nib.Nibble(2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := nib.AlignTo(16); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+	if nib.BitsRead() != 2 {
+		t.Errorf("expected no bits skipped, got position %d", nib.BitsRead())
+	}
+}
+
+func TestReadAlignedStruct(t *testing.T) {
+	b := []byte{0xE0, 0xDE, 0xAD, 0xBE, 0xEF, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09}
+	nib := nibs.New(bytes.NewReader(b))
+
+	// 3-bit preamble
+	if v, err := nib.Nibble(3); err != nil || v != 7 {
+		t.Fatalf("expected 7, got %d and error `%v`", v, err)
+	}
+	p := make([]byte, 4)
+	start, err := nib.ReadAlignedStruct(p)
+	if err != nil || start != 8 {
+		t.Errorf("expected struct at bit 8, got %d and error `%v`", start, err)
+	}
+	if !bytes.Equal(p, []byte{0xDE, 0xAD, 0xBE, 0xEF}) {
+		t.Errorf("expected deadbeef, got %x", p)
+	}
+
+	// already aligned, spanning more than one 64-bit read
+	p = make([]byte, 9)
+	start, err = nib.ReadAlignedStruct(p)
+	if err != nil || start != 40 {
+		t.Errorf("expected struct at bit 40, got %d and error `%v`", start, err)
+	}
+	if !bytes.Equal(p, b[5:]) {
+		t.Errorf("expected %x, got %x", b[5:], p)
+	}
+
+	if _, err := nib.ReadAlignedStruct(p[:1]); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestReadAlignedStructTruncated(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(bytes.Repeat([]byte{0xFF}, 12)))
+	nib.Nibble(1)
+	if _, err := nib.ReadAlignedStruct(make([]byte, 12)); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+}
+
+func TestNibbleAlignedField(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xAB, 0xCD, 0xEF}))
+
+	if v, err := nib.NibbleAlignedField(8, 4); err != nil || v != 0xA {
+		t.Errorf("expected 0xA, got %#x and error `%v`", v, err)
+	}
+	if v, err := nib.NibbleAlignedField(4, 3); err != nil || v != 0x5 {
+		t.Errorf("expected 0x5, got %#x and error `%v`", v, err)
+	}
+
+	// off by one bit
+	_, err := nib.NibbleAlignedField(8, 8)
+	if !errors.Is(err, nibs.ErrMisaligned) {
+		t.Errorf("expected `nibs.ErrMisaligned`, got `%v`", err)
+	}
+	if nib.BitsRead() != 7 {
+		t.Errorf("expected no bits consumed, got position %d", nib.BitsRead())
+	}
+
+	nib.Nibble(1)
+	if v, err := nib.NibbleAlignedField(16, 8); !errors.Is(err, nibs.ErrMisaligned) || v != 0 {
+		t.Errorf("expected `nibs.ErrMisaligned`, got %#x and `%v`", v, err)
+	}
+	if v, err := nib.NibbleAlignedField(8, 16); err != nil || v != 0xCDEF {
+		t.Errorf("expected 0xCDEF, got %#x and error `%v`", v, err)
+	}
+	if _, err := nib.NibbleAlignedField(0, 8); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
+
+func TestNibblePaddedRecord(t *testing.T) {
+	// XDR style: 32-bit length, 5 bytes of data, 3 bytes of padding, then a
+	// second record with no padding
+	b := []byte{0, 0, 0, 5, 'h', 'e', 'l', 'l', 'o', 0, 0, 0, 0, 0, 0, 4, 'a', 'b', 'c', 'd'}
+	nib := nibs.New(bytes.NewReader(b))
+	for _, expected := range []string{"hello", "abcd"} {
+		data, err := nib.NibblePaddedRecord(32, 4)
+		if err != nil || string(data) != expected {
+			t.Errorf("expected %q, got %q and error `%v`", expected, data, err)
+		}
+	}
+	if _, err := nib.NibblePaddedRecord(32, 4); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+
+	// an 8-bit length pads the 6 byte record to 8 bytes
+	nib = nibs.New(bytes.NewReader([]byte{5, 'h', 'e', 'l', 'l', 'o', 0, 0, 0xFF}))
+	if data, err := nib.NibblePaddedRecord(8, 4); err != nil || string(data) != "hello" {
+		t.Errorf("expected \"hello\", got %q and error `%v`", data, err)
+	}
+	if v, err := nib.Nibble(8); err != nil || v != 0xFF {
+		t.Errorf("expected 0xff after the padding, got %#x and error `%v`", v, err)
+	}
+}
+
+func TestNibblePaddedRecordHugeLength(t *testing.T) {
+	// a 64-bit length no stream can hold, with the end of the stream known
+	nib := nibs.New(bytes.NewReader(bytes.Repeat([]byte{0xFF}, 12)))
+	if _, err := nib.NibblePaddedRecord(64, 4); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+
+	// a 4 GiB length before the end of the stream is known is read, not
+	// preallocated, until the stream runs out
+	b := make([]byte, 64<<10)
+	copy(b, []byte{0xFF, 0xFF, 0xFF, 0xFF})
+	nib = nibs.New(bytes.NewReader(b))
+	if _, err := nib.NibblePaddedRecord(32, 4); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+}
+
+func TestNibblePaddedRecordErrors(t *testing.T) {
+	b := []byte{5, 'h', 'e', 'l', 'l', 'o', 0, 0x80}
+
+	nib := nibs.New(bytes.NewReader(b))
+	if _, err := nib.NibblePaddedRecord(8, 4); !errors.Is(err, nibs.ErrNonZeroPadding) {
+		t.Errorf("expected `nibs.ErrNonZeroPadding`, got `%v`", err)
+	}
+	nib = nibs.New(bytes.NewReader(b), nibs.WithLenientPadding())
+	if data, err := nib.NibblePaddedRecord(8, 4); err != nil || string(data) != "hello" {
+		t.Errorf("expected \"hello\" with lenient padding, got %q and error `%v`", data, err)
+	}
+
+	// truncated data and padding
+	for _, size := range []int{4, 7} {
+		nib = nibs.New(bytes.NewReader(b[:size]))
+		if _, err := nib.NibblePaddedRecord(8, 4); err != io.ErrUnexpectedEOF {
+			t.Errorf("expected `io.ErrUnexpectedEOF` for %d bytes, got `%v`", size, err)
+		}
+	}
+
+	nib = nibs.New(bytes.NewReader(b), nibs.WithMaxAlloc(4))
+	if _, err := nib.NibblePaddedRecord(8, 4); !errors.Is(err, nibs.ErrAllocLimit) {
+		t.Errorf("expected `nibs.ErrAllocLimit`, got `%v`", err)
+	}
+	if _, err := nib.NibblePaddedRecord(8, 0); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
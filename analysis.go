@@ -0,0 +1,107 @@
+package nibs
+
+import (
+	"errors"
+	"io"
+	"math"
+	"math/bits"
+)
+
+// ShannonEntropy reads the remainder of the stream in symbols of `symbolBits`
+// bits and returns the Shannon entropy of the symbol distribution, in bits per
+// symbol. The result ranges from 0 for a constant stream to `symbolBits` for a
+// uniformly distributed one.
+//
+// The stream is drained. Trailing bits too few to make a full symbol are not
+// counted and are left unread (see `BitsRemaining`). An empty remainder has
+// an entropy of 0.
+//
+// `symbolBits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+func (n *Nibs) ShannonEntropy(symbolBits int) (float64, error) {
+	if symbolBits < 1 || symbolBits > 64 {
+		return 0, n.sizeError("ShannonEntropy", symbolBits, 64)
+	}
+	defer n.withoutZeroFill()()
+
+	freq := make(map[uint64]int)
+	var total int
+	for {
+		v, err := n.Nibble(symbolBits)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		freq[v]++
+		total++
+	}
+
+	var entropy float64
+	for _, count := range freq {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy, nil
+}
+
+// MinWidth reads the remainder of the stream in symbols of `symbolBits` bits
+// and returns the number of bits needed to hold the largest symbol, which is
+// the narrowest width the symbols could be repacked into. A stream of only
+// zero symbols, or an empty remainder, has a width of 0.
+//
+// The stream is drained. Trailing bits too few to make a full symbol are not
+// counted and are left unread (see `BitsRemaining`).
+//
+// `symbolBits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+func (n *Nibs) MinWidth(symbolBits int) (int, error) {
+	if symbolBits < 1 || symbolBits > 64 {
+		return 0, n.sizeError("MinWidth", symbolBits, 64)
+	}
+	defer n.withoutZeroFill()()
+
+	var max uint64
+	for {
+		v, err := n.Nibble(symbolBits)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return bits.Len64(max), nil
+}
+
+// CountRemaining reads the remainder of the stream in values of `bits` bits
+// and returns the number of complete values, such as to size an array field
+// that runs to the end of the stream.
+//
+// The stream is drained. Trailing bits too few to make a full value are not
+// counted and are left unread (see `BitsRemaining`).
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+func (n *Nibs) CountRemaining(bits int) (int, error) {
+	if bits < 1 || bits > 64 {
+		return 0, n.sizeError("CountRemaining", bits, 64)
+	}
+	defer n.withoutZeroFill()()
+
+	var count int
+	for {
+		_, err := n.nibble(bits)
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+}
@@ -0,0 +1,117 @@
+package nibs_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestShannonEntropy(t *testing.T) {
+	// every byte value the same number of times
+	uniform := make([]byte, 256*4)
+	for i := range uniform {
+		uniform[i] = byte(i)
+	}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		symbolBits int
+		expected   float64
+	}{
+		{name: "uniform bytes", data: uniform, symbolBits: 8, expected: 8},
+		{name: "uniform nibbles", data: uniform, symbolBits: 4, expected: 4},
+		{name: "constant", data: bytes.Repeat([]byte{0x5A}, 1000), symbolBits: 8, expected: 0},
+		{name: "empty", data: nil, symbolBits: 8, expected: 0},
+	}
+
+	for _, tt := range tests {
+		nib := nibs.New(bytes.NewReader(tt.data))
+		e, err := nib.ShannonEntropy(tt.symbolBits)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.name, err)
+			continue
+		}
+		if math.Abs(e-tt.expected) > 1e-9 {
+			t.Errorf("expected entropy %f for %s, got %f", tt.expected, tt.name, e)
+		}
+	}
+}
+
+func TestShannonEntropyPartialSymbol(t *testing.T) {
+	// two 7-bit symbols leaves 2 trailing bits uncounted
+	nib := nibs.New(bytes.NewReader([]byte{0x00, 0x00}))
+	e, err := nib.ShannonEntropy(7)
+	if err != nil || e != 0 {
+		t.Errorf("expected entropy 0, got %f and error `%v`", e, err)
+	}
+	if r, err := nib.BitsRemaining(); err != nil || r != 2 {
+		t.Errorf("expected 2 bits remaining, got %d and error `%v`", r, err)
+	}
+}
+
+func TestMinWidth(t *testing.T) {
+	// 8-bit symbols no larger than 31, plus a trailing partial symbol
+	data := []byte{3, 17, 0, 31, 9, 0xFF}
+	nib := nibs.New(bytes.NewReader(data))
+	if err := nib.Skip(4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// unaligned by 4 bits, symbols are 0x31, 0x10, 0x01, 0xF0, 0x9F
+	if w, err := nib.MinWidth(8); err != nil || w != 8 {
+		t.Errorf("expected width 8, got %d and error `%v`", w, err)
+	}
+	if left, err := nib.BitsRemaining(); err != nil || left != 4 {
+		t.Errorf("expected 4 bits left unread, got %d and error `%v`", left, err)
+	}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		symbolBits int
+		expected   int
+	}{
+		{name: "fits in 5 bits", data: data[:5], symbolBits: 8, expected: 5},
+		{name: "nibbles", data: []byte{0x12, 0x31}, symbolBits: 4, expected: 2},
+		{name: "zeros", data: make([]byte, 10), symbolBits: 8, expected: 0},
+		{name: "empty", data: nil, symbolBits: 8, expected: 0},
+		{name: "full width", data: bytes.Repeat([]byte{0xFF}, 8), symbolBits: 64, expected: 64},
+	}
+	for _, tt := range tests {
+		nib := nibs.New(bytes.NewReader(tt.data))
+		if w, err := nib.MinWidth(tt.symbolBits); err != nil || w != tt.expected {
+			t.Errorf("%s: expected width %d, got %d and error `%v`", tt.name, tt.expected, w, err)
+		}
+	}
+
+	if _, err := nib.MinWidth(0); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
+
+func TestCountRemaining(t *testing.T) {
+	// 47 bytes hold 37 10-bit values and a trailing partial of 6 bits
+	data := make([]byte, 47)
+	nib := nibs.New(bytes.NewReader(data))
+	if c, err := nib.CountRemaining(10); err != nil || c != 37 {
+		t.Errorf("expected 37, got %d and error `%v`", c, err)
+	}
+	if left, err := nib.BitsRemaining(); err != nil || left != 6 {
+		t.Errorf("expected 6 bits left unread, got %d and error `%v`", left, err)
+	}
+
+	// exactly 37 values with none left over
+	nib = nibs.New(bytes.NewReader(make([]byte, 37)))
+	if c, err := nib.CountRemaining(8); err != nil || c != 37 {
+		t.Errorf("expected 37, got %d and error `%v`", c, err)
+	}
+	if c, err := nib.CountRemaining(8); err != nil || c != 0 {
+		t.Errorf("expected 0 when drained, got %d and error `%v`", c, err)
+	}
+
+	if _, err := nib.CountRemaining(65); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
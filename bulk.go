@@ -0,0 +1,335 @@
+package nibs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxPrealloc is the most elements preallocated for a slice sized by a length
+// read from the stream. Larger slices grow as values are read, so a bogus
+// length in a truncated stream does not allocate up front.
+const maxPrealloc = 4096
+
+// nibbleBytes reads `length` bytes, a length read from the stream, as one
+// part of a multi-part field. As for `NibblePackedArray`, a length larger than
+// the stream can hold returns io.ErrUnexpectedEOF without allocating once the
+// end of the stream is known, one over the `WithMaxAlloc` limit returns an
+// error wrapping ErrAllocLimit, and otherwise the bytes are preallocated only
+// up to maxPrealloc.
+func (n *Nibs) nibbleBytes(length uint64) ([]byte, error) {
+	if left, err := n.BitsRemaining(); err == nil && length > uint64(left)/8 {
+		return nil, n.wrap(io.ErrUnexpectedEOF)
+	}
+	if err := n.checkAlloc(length, 1); err != nil {
+		return nil, err
+	}
+
+	prealloc := length
+	if prealloc > maxPrealloc {
+		prealloc = maxPrealloc
+	}
+	data := make([]byte, 0, prealloc)
+	for i := uint64(0); i < length; i++ {
+		b, err := n.Nibble8(8)
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		data = append(data, b)
+	}
+	return data, nil
+}
+
+// NibbleChunkedField reads up to `chunk` values of `bits` bits each and
+// returns them as a slice. The returned bool is true if the end of the stream
+// was reached before `chunk` values were read, in which case the slice holds
+// the values read up to that point and any trailing bits too few to make a
+// full value are left unread (see `BitsRemaining`).
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+//
+// Any error other than io.EOF is returned along with the values read before it.
+func (n *Nibs) NibbleChunkedField(bits, chunk int) (vals []uint64, complete bool, err error) {
+	defer n.field(&err)()
+
+	if bits < 1 || bits > 64 {
+		return nil, false, n.sizeError("NibbleChunkedField", bits, 64)
+	}
+	if chunk < 0 {
+		chunk = 0
+	}
+
+	vals = make([]uint64, 0, chunk)
+	for len(vals) < chunk {
+		v, err := n.Nibble(bits)
+		if errors.Is(err, io.EOF) {
+			return vals, true, nil
+		}
+		if err != nil {
+			return vals, false, err
+		}
+		vals = append(vals, v)
+	}
+	return vals, false, nil
+}
+
+// NibblePackedArray reads an array of values stored with a shared bit width:
+// a `widthBits`-bit width W, then a `countBits`-bit count C, then C values of
+// W bits each.
+//
+// `widthBits` must be in the range 1 to 7 inclusive and `countBits` in the
+// range 1 to 64 inclusive, otherwise nibs.ErrNibbleSize is returned. An error
+// wrapping nibs.ErrNibbleSize is also returned if W is 0 or greater than 64.
+//
+// If the count is larger than the stream can hold, io.ErrUnexpectedEOF is
+// returned without allocating, provided the end of the stream is already
+// known (see `BitsRemaining`). The option `WithMaxAlloc` limits the
+// allocation regardless.
+//
+// io.EOF is returned if the stream is exhausted before the header, and
+// io.ErrUnexpectedEOF if it ends part way through the array.
+func (n *Nibs) NibblePackedArray(widthBits, countBits int) (vals []uint64, err error) {
+	defer n.field(&err)()
+
+	if widthBits < 1 || widthBits > 7 {
+		return nil, n.sizeError("NibblePackedArray", widthBits, 7)
+	}
+	if countBits < 1 || countBits > 64 {
+		return nil, n.sizeError("NibblePackedArray", countBits, 64)
+	}
+	width, err := n.Nibble(widthBits)
+	if err != nil {
+		return nil, err
+	}
+	count, err := n.Nibble(countBits)
+	if err != nil {
+		return nil, unexpected(err)
+	}
+	if width < 1 || width > 64 {
+		return nil, n.wrap(fmt.Errorf("%w: packed array width %d", ErrNibbleSize, width))
+	}
+
+	if left, err := n.BitsRemaining(); err == nil && count > uint64(left)/width {
+		return nil, n.wrap(io.ErrUnexpectedEOF)
+	}
+	if err := n.checkAlloc(count, 8); err != nil {
+		return nil, err
+	}
+
+	prealloc := count
+	if prealloc > maxPrealloc {
+		prealloc = maxPrealloc
+	}
+	vals = make([]uint64, 0, prealloc)
+	for i := uint64(0); i < count; i++ {
+		v, err := n.Nibble(int(width))
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
+
+// varWidthBits is the size of the width header of each `NibbleVarWidthList` entry.
+const varWidthBits = 6
+
+// NibbleVarWidthList reads a list of values each stored with its own bit
+// width: a `countBits`-bit count C, then C entries of a 6-bit width W followed
+// by a value of W bits. A W of 0 is the value 0 with no value bits, so values
+// may be up to 63 bits.
+//
+// `countBits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+//
+// If the count is larger than the stream can hold, io.ErrUnexpectedEOF is
+// returned without allocating, provided the end of the stream is already
+// known (see `BitsRemaining`). The option `WithMaxAlloc` limits the
+// allocation regardless.
+//
+// io.EOF is returned if the stream is exhausted before the count, and
+// io.ErrUnexpectedEOF if it ends part way through the list.
+func (n *Nibs) NibbleVarWidthList(countBits int) (vals []uint64, err error) {
+	defer n.field(&err)()
+
+	count, err := n.Nibble(countBits)
+	if err != nil {
+		return nil, err
+	}
+
+	if left, err := n.BitsRemaining(); err == nil && count > uint64(left)/varWidthBits {
+		return nil, n.wrap(io.ErrUnexpectedEOF)
+	}
+	if err := n.checkAlloc(count, 8); err != nil {
+		return nil, err
+	}
+
+	prealloc := count
+	if prealloc > maxPrealloc {
+		prealloc = maxPrealloc
+	}
+	vals = make([]uint64, 0, prealloc)
+	for i := uint64(0); i < count; i++ {
+		width, err := n.Nibble(varWidthBits)
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		var v uint64
+		if width > 0 {
+			if v, err = n.Nibble(int(width)); err != nil {
+				return nil, unexpected(err)
+			}
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
+
+// NibbleAlignedWords reads `count` values of `bitsEach` bits each and returns
+// them packed into 64-bit words with each value in a slot of its own that
+// starts on a `wordAlign`-bit boundary, ready for vectorized code that
+// expects fixed lanes.
+//
+// Each slot is `bitsEach` rounded up to a multiple of `wordAlign` bits, and
+// holds its value zero extended. Slots are filled from the least significant
+// bits of the first word upwards, so on a little-endian machine the words
+// viewed as memory hold the values in stream order. For example, 6-bit values
+// aligned to 8 bits become one value per byte, eight to a word. A slot that
+// does not fit in the rest of a word continues in the next one, and unused
+// bits in the last word are zero.
+//
+// `bitsEach` and `wordAlign` must be in the range 1 to 64 inclusive and
+// `count` must not be negative, otherwise nibs.ErrNibbleSize is returned.
+// io.EOF is returned if the stream is exhausted before the first value, and
+// io.ErrUnexpectedEOF if it ends part way through.
+func (n *Nibs) NibbleAlignedWords(count, bitsEach int, wordAlign int) (words []uint64, err error) {
+	defer n.field(&err)()
+
+	if bitsEach < 1 || bitsEach > 64 {
+		return nil, n.sizeError("NibbleAlignedWords", bitsEach, 64)
+	}
+	if wordAlign < 1 || wordAlign > 64 {
+		return nil, n.sizeError("NibbleAlignedWords", wordAlign, 64)
+	}
+	if count < 0 {
+		return nil, n.misuseError("NibbleAlignedWords", fmt.Sprintf("a count of %d", count))
+	}
+	slot := (bitsEach + wordAlign - 1) / wordAlign * wordAlign
+	size := (count*slot + 63) / 64
+	if err := n.checkAlloc(uint64(size), 8); err != nil {
+		return nil, err
+	}
+
+	words = make([]uint64, size)
+	for i := 0; i < count; i++ {
+		v, err := n.Nibble(bitsEach)
+		if err != nil {
+			if i > 0 {
+				err = unexpected(err)
+			}
+			return nil, err
+		}
+		off := i * slot
+		w, shift := off/64, uint(off%64)
+		words[w] |= v << shift
+		if int(shift)+bitsEach > 64 {
+			words[w+1] |= v >> (64 - shift)
+		}
+	}
+	return words, nil
+}
+
+// readPacked reads `count` values of `bitsEach` bits each, several at a time
+// where they fit in 64 bits, and passes each value to `store` along with its
+// index. It returns the number of values read, which is less than `count` only
+// along with an error. Near the end of the stream, or when the option
+// `WithAutoAlign` or `WithValueTrace` is used, it reads one value at a time, so
+// every complete value is read and each value is aligned and traced as by
+// `Nibble`.
+func (n *Nibs) readPacked(count, bitsEach int, store func(i int, v uint64)) (int, error) {
+	defer n.withoutZeroFill()()
+
+	mask := ^uint64(0) >> uint(64-bitsEach)
+	per := 64 / bitsEach // values per read
+	if n.autoAlign > 1 || n.tracing {
+		per = 1
+	}
+	for i := 0; i < count; {
+		k := count - i
+		if k > per {
+			k = per
+		}
+		v, err := n.Nibble(k * bitsEach)
+		if errors.Is(err, io.EOF) && k > 1 {
+			// near the end of the stream; read one value at a time
+			per = 1
+			continue
+		}
+		if err != nil {
+			return i, err
+		}
+		for j := k - 1; j >= 0; j-- {
+			store(i+j, v&mask)
+			v >>= uint(bitsEach % 64)
+		}
+		i += k
+	}
+	return count, nil
+}
+
+// NibbleTriStateSlice reads `count` 2-bit values, such as tri-state flags
+// where the fourth value is reserved, and returns them as a slice holding 0
+// to 3. Up to 32 values are read at a time.
+//
+// `count` must not be negative, otherwise nibs.ErrNibbleSize is returned. If
+// fewer than `count` values remain, the values read are returned along with
+// io.EOF if none were read, or io.ErrUnexpectedEOF otherwise.
+func (n *Nibs) NibbleTriStateSlice(count int) ([]int8, error) {
+	if count < 0 {
+		return nil, n.misuseError("NibbleTriStateSlice", fmt.Sprintf("a count of %d", count))
+	}
+
+	vals := make([]int8, count)
+	c, err := n.readPacked(count, 2, func(i int, v uint64) { vals[i] = int8(v) })
+	if err != nil && c > 0 {
+		err = unexpected(err)
+	}
+	return vals[:c], err
+}
+
+// NibbleMixed reads len(`widths`) values into `dst`, each of the number of
+// bits given by the corresponding entry of `widths`, such as the fields of a
+// record, and returns the number of values read.
+//
+// Every width must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned and nothing is read. `dst` must be the same
+// length as `widths`: an error wrapping io.ErrShortBuffer is returned if it is
+// shorter, and nibs.ErrNibbleSize if it is longer. If the stream ends part
+// way, the values read before it are in `dst` and their number is returned
+// along with io.EOF if none were read, or io.ErrUnexpectedEOF otherwise.
+func (n *Nibs) NibbleMixed(dst []uint64, widths []int) (int, error) {
+	for _, w := range widths {
+		if w < 1 || w > 64 {
+			return 0, n.sizeError("NibbleMixed", w, 64)
+		}
+	}
+	if len(dst) < len(widths) {
+		return 0, n.shortError("NibbleMixed", len(widths), len(dst))
+	}
+	if len(dst) > len(widths) {
+		return 0, n.misuseError("NibbleMixed", fmt.Sprintf("a destination of %d for %d widths; lengths must match", len(dst), len(widths)))
+	}
+
+	for i, w := range widths {
+		v, err := n.Nibble(w)
+		if err != nil {
+			if i > 0 {
+				err = unexpected(err)
+			}
+			return i, err
+		}
+		dst[i] = v
+	}
+	return len(widths), nil
+}
@@ -0,0 +1,273 @@
+package nibs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestNibbleChunkedField(t *testing.T) {
+	// 10 bytes holds eight 10-bit values
+	b := []byte{0x00, 0x40, 0x20, 0x0C, 0x04, 0x01, 0x40, 0x60, 0x1C, 0x08}
+	nib := nibs.New(bytes.NewReader(b))
+
+	// exactly `chunk` values with data remaining
+	vals, eof, err := nib.NibbleChunkedField(10, 5)
+	if err != nil || eof {
+		t.Fatalf("expected no error and eof=false, got `%v` and eof=%t", err, eof)
+	}
+	expected := []uint64{1, 2, 3, 4, 5}
+	if !equalUint64s(vals, expected) {
+		t.Errorf("expected %v, got %v", expected, vals)
+	}
+
+	// EOF truncates before `chunk`
+	vals, eof, err = nib.NibbleChunkedField(10, 5)
+	if err != nil || !eof {
+		t.Fatalf("expected no error and eof=true, got `%v` and eof=%t", err, eof)
+	}
+	expected = []uint64{6, 7, 8}
+	if !equalUint64s(vals, expected) {
+		t.Errorf("expected %v, got %v", expected, vals)
+	}
+
+	// nothing left
+	vals, eof, err = nib.NibbleChunkedField(10, 5)
+	if err != nil || !eof || len(vals) != 0 {
+		t.Errorf("expected no values and eof=true, got %v, eof=%t and error `%v`", vals, eof, err)
+	}
+}
+
+func equalUint64s(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestNibblePackedArray(t *testing.T) {
+	// width 5, count 4, then 3, 17, 31, 0 followed by 11 bits to end the stream
+	b := PackBits("00101 0100 00011 10001 11111 00000 10100000000")
+	nib := nibs.New(bytes.NewReader(b))
+
+	vals, err := nib.NibblePackedArray(5, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []uint64{3, 17, 31, 0}
+	if !equalUint64s(vals, expected) {
+		t.Errorf("expected %v, got %v", expected, vals)
+	}
+	if v, err := nib.Nibble(11); err != nil || v != 0x500 {
+		t.Errorf("expected 0x500, got %#x and error `%v`", v, err)
+	}
+	if _, err := nib.NibblePackedArray(5, 4); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestNibblePackedArrayErrors(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(PackBits("00101 0100")))
+	if _, err := nib.NibblePackedArray(0, 4); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, err := nib.NibblePackedArray(5, 65); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+
+	// header promises more values than the stream holds
+	if _, err := nib.NibblePackedArray(5, 4); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+
+	// width out of range
+	nib = nibs.New(bytes.NewReader(PackBits("1000001 0001 0000000")))
+	if _, err := nib.NibblePackedArray(7, 4); !errors.Is(err, nibs.ErrNibbleSize) {
+		t.Errorf("expected error wrapping `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	nib = nibs.New(bytes.NewReader(PackBits("0000000 0001 0000000")))
+	if _, err := nib.NibblePackedArray(7, 4); !errors.Is(err, nibs.ErrNibbleSize) {
+		t.Errorf("expected error wrapping `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+
+	// huge count caught by the allocation limit before the end of stream is known
+	b := append(PackBits("01000 "+strings.Repeat("1", 32)), make([]byte, 1024)...)
+	nib = nibs.New(bytes.NewReader(b), nibs.WithMaxAlloc(1<<20))
+	if _, err := nib.NibblePackedArray(5, 32); !errors.Is(err, nibs.ErrAllocLimit) {
+		t.Errorf("expected `nibs.ErrAllocLimit`, got `%v`", err)
+	}
+
+	// huge count without a limit fails at the end of the stream
+	nib = nibs.New(bytes.NewReader(b))
+	if _, err := nib.NibblePackedArray(5, 32); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+}
+
+func TestNibbleAlignedWords(t *testing.T) {
+	// ten 6-bit values, one per byte, eight bytes to a word
+	ba := &BitArray{}
+	for i := uint64(0); i < 10; i++ {
+		ba.AddVar((i*6+1)<<58, 6)
+	}
+	nib := nibs.New(bytes.NewReader(ba.Bytes()))
+	words, err := nib.NibbleAlignedWords(10, 6, 8)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []uint64{0x2B25_1F19_130D_0701, 0x0000_0000_0000_3731}
+	if !equalUint64s(words, expected) {
+		t.Errorf("expected %#x, got %#x", expected, words)
+	}
+
+	// 20-bit values in 24-bit slots, the third straddling two words
+	nib = nibs.New(bytes.NewReader(bytes.Repeat([]byte{0xFF}, 8)))
+	words, err = nib.NibbleAlignedWords(3, 20, 8)
+	expected = []uint64{0xFFFF_0FFF_FF0F_FFFF, 0xF}
+	if err != nil || !equalUint64s(words, expected) {
+		t.Errorf("expected %#x, got %#x and error `%v`", expected, words, err)
+	}
+}
+
+func TestNibbleAlignedWordsErrors(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(make([]byte, 4)))
+	if _, err := nib.NibbleAlignedWords(5, 7, 8); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+	if _, err := nib.NibbleAlignedWords(1, 64, 64); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+	if _, err := nib.NibbleAlignedWords(1, 6, 0); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+
+	nib = nibs.New(bytes.NewReader(make([]byte, 4)), nibs.WithMaxAlloc(64))
+	if _, err := nib.NibbleAlignedWords(100, 6, 8); !errors.Is(err, nibs.ErrAllocLimit) {
+		t.Errorf("expected `nibs.ErrAllocLimit`, got `%v`", err)
+	}
+}
+
+func TestNibbleVarWidthList(t *testing.T) {
+	// count 4, then entries of widths 3, 10, 1 and 0
+	b := PackBits("0100 000011 101 001010 1111000011 000001 1 000000 00000")
+	nib := nibs.New(bytes.NewReader(b))
+	vals, err := nib.NibbleVarWidthList(4)
+	expected := []uint64{5, 0x3C3, 1, 0}
+	if err != nil || !equalUint64s(vals, expected) {
+		t.Errorf("expected %v, got %v and error `%v`", expected, vals, err)
+	}
+	// 6 bits of padding remain
+	if _, err := nib.NibbleVarWidthList(8); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestNibbleVarWidthListErrors(t *testing.T) {
+	// truncated within the value of the second entry
+	nib := nibs.New(bytes.NewReader(PackBits("0010 000011 101 010100 111100")))
+	if _, err := nib.NibbleVarWidthList(4); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+
+	// a count too large for the stream fails before allocating
+	nib = nibs.New(bytes.NewReader(PackBits("11111111 11111111 11111111 11111111 000001 1")))
+	if _, err := nib.NibbleVarWidthList(32); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+	nib = nibs.New(bytes.NewReader(bytes.Repeat([]byte{0xFF}, 100)), nibs.WithMaxAlloc(64))
+	if _, err := nib.NibbleVarWidthList(16); !errors.Is(err, nibs.ErrAllocLimit) {
+		t.Errorf("expected `nibs.ErrAllocLimit`, got `%v`", err)
+	}
+
+	if _, err := nib.NibbleVarWidthList(0); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
+
+func TestNibbleTriStateSlice(t *testing.T) {
+	ba := &BitArray{}
+	for i := 0; i < 100; i++ {
+		ba.AddVar(uint64(i*7%4)<<62, 2)
+	}
+	data := ba.Bytes()
+
+	nib := nibs.New(bytes.NewReader(data), nibs.WithBufferSize(3))
+	vals, err := nib.NibbleTriStateSlice(100)
+	if err != nil || len(vals) != 100 {
+		t.Fatalf("expected 100 values, got %d and error `%v`", len(vals), err)
+	}
+	for i, v := range vals {
+		if v != int8(i*7%4) {
+			t.Errorf("value %d: expected %d, got %d", i, i*7%4, v)
+		}
+	}
+	if vals, err := nib.NibbleTriStateSlice(1); err != io.EOF || len(vals) != 0 {
+		t.Errorf("expected no values and `io.EOF`, got %d and `%v`", len(vals), err)
+	}
+
+	// truncated part way through a 32-value read
+	nib = nibs.New(bytes.NewReader(data))
+	vals, err = nib.NibbleTriStateSlice(102)
+	if err != io.ErrUnexpectedEOF || len(vals) != 100 {
+		t.Errorf("expected 100 values and `io.ErrUnexpectedEOF`, got %d and `%v`", len(vals), err)
+	}
+
+	if _, err := nib.NibbleTriStateSlice(-1); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
+
+func TestNibbleMixed(t *testing.T) {
+	// fields of 3, 13, 1, 64 and 7 bits
+	ba := &BitArray{}
+	ba.AddVar(0x5<<61, 3)
+	ba.AddVar(0x1ABC<<51, 13)
+	ba.AddVar(1<<63, 1)
+	ba.Add64(0x0123456789ABCDEF)
+	ba.AddVar(0x55<<57, 7)
+	nib := nibs.New(bytes.NewReader(ba.Bytes()))
+
+	dst := make([]uint64, 5)
+	c, err := nib.NibbleMixed(dst, []int{3, 13, 1, 64, 7})
+	if err != nil || c != 5 {
+		t.Fatalf("expected 5 values, got %d and error `%v`", c, err)
+	}
+	if !equalUint64s(dst, []uint64{0x5, 0x1ABC, 1, 0x0123456789ABCDEF, 0x55}) {
+		t.Errorf("unexpected values %#x", dst)
+	}
+}
+
+func TestNibbleMixedErrors(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xAB, 0xCD, 0xEF}))
+	dst := make([]uint64, 4)
+
+	if _, err := nib.NibbleMixed(dst, []int{8, 0}); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, err := nib.NibbleMixed(dst[:1], []int{8, 8}); !errors.Is(err, io.ErrShortBuffer) {
+		t.Errorf("expected `io.ErrShortBuffer`, got `%v`", err)
+	}
+	if _, err := nib.NibbleMixed(dst, []int{8, 8}); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize` for a longer destination, got `%v`", err)
+	}
+
+	// truncated at the third field
+	c, err := nib.NibbleMixed(dst[:3], []int{4, 12, 16})
+	if err != io.ErrUnexpectedEOF || c != 2 || dst[0] != 0xA || dst[1] != 0xBCD {
+		t.Errorf("expected [0xa 0xbcd] and `io.ErrUnexpectedEOF`, got %#x and `%v`", dst[:c], err)
+	}
+	if c, err := nib.NibbleMixed(dst[:1], []int{16}); err != io.EOF || c != 0 {
+		t.Errorf("expected no values and `io.EOF`, got %d and `%v`", c, err)
+	}
+}
@@ -0,0 +1,102 @@
+package nibs
+
+import "fmt"
+
+// ErrSignalRange is the error used when a CAN signal does not fit within its frame.
+var ErrSignalRange = newSentinel("signal outside frame")
+
+// Signal describes a CAN signal within a frame, as defined by a DBC file.
+//
+// Bits within the frame are numbered as in DBC files: bit 8*i+j is bit j of
+// byte i, where bit 0 is the least significant bit of the byte.
+type Signal struct {
+	// StartBit is the bit number of the least significant bit of the signal for
+	// Intel (little-endian) byte order, or of the most significant bit for
+	// Motorola (big-endian) byte order.
+	StartBit int
+	// Length is the length of the signal in bits, 1 to 64 inclusive.
+	Length int
+	// BigEndian selects Motorola byte order, shown as @0 in a DBC file.
+	// Otherwise Intel byte order is used, shown as @1.
+	BigEndian bool
+	// Signed is true if the raw value is two's complement, shown as - in a DBC file.
+	Signed bool
+	// Scale and Offset convert the raw value to the physical value as
+	// raw*Scale + Offset. A zero Scale is treated as 1.
+	Scale  float64
+	Offset float64
+}
+
+// DecodeSignal extracts the signal `s` from a CAN frame, typically 8 bytes or
+// 64 bytes for CAN FD, and returns its physical value.
+//
+// nibs.ErrNibbleSize is returned if the signal length is not in the range
+// 1 to 64 inclusive, and an error wrapping ErrSignalRange if any bit of the
+// signal lies outside the frame.
+func DecodeSignal(frame []byte, s Signal) (float64, error) {
+	raw, err := signalRaw(frame, s)
+	if err != nil {
+		return 0, err
+	}
+
+	scale := s.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	if s.Signed {
+		return float64(signExtend(raw, s.Length))*scale + s.Offset, nil
+	}
+	return float64(raw)*scale + s.Offset, nil
+}
+
+// DecodeSignals extracts each of `signals` from a CAN frame and returns their
+// physical values in the same order. See `DecodeSignal` for details.
+func DecodeSignals(frame []byte, signals []Signal) ([]float64, error) {
+	vals := make([]float64, len(signals))
+	for i, s := range signals {
+		v, err := DecodeSignal(frame, s)
+		if err != nil {
+			return nil, fmt.Errorf("signal %d: %w", i, err)
+		}
+		vals[i] = v
+	}
+	return vals, nil
+}
+
+// signalRaw returns the raw unsigned bits of signal `s` within `frame`.
+func signalRaw(frame []byte, s Signal) (uint64, error) {
+	if s.Length < 1 || s.Length > 64 {
+		return 0, ErrNibbleSize
+	}
+	frameBits := len(frame) * 8
+
+	if !s.BigEndian {
+		// Intel: the signal occupies consecutive bit numbers from the LSB upwards
+		if s.StartBit < 0 || s.StartBit+s.Length > frameBits {
+			return 0, fmt.Errorf("%w: start bit %d, length %d, frame of %d bytes", ErrSignalRange, s.StartBit, s.Length, len(frame))
+		}
+		var raw uint64
+		for k := s.Length - 1; k >= 0; k-- {
+			pos := s.StartBit + k
+			raw = raw<<1 | uint64(frame[pos/8]>>uint(pos%8)&1)
+		}
+		return raw, nil
+	}
+
+	// Motorola: starting at the MSB, walk down each byte and continue from the
+	// top of the next byte, giving the sawtooth bit numbering
+	var raw uint64
+	pos := s.StartBit
+	for k := 0; k < s.Length; k++ {
+		if pos < 0 || pos >= frameBits {
+			return 0, fmt.Errorf("%w: start bit %d, length %d, frame of %d bytes", ErrSignalRange, s.StartBit, s.Length, len(frame))
+		}
+		raw = raw<<1 | uint64(frame[pos/8]>>uint(pos%8)&1)
+		if pos%8 == 0 {
+			pos += 15
+		} else {
+			pos--
+		}
+	}
+	return raw, nil
+}
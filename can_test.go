@@ -0,0 +1,114 @@
+package nibs_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestDecodeSignal(t *testing.T) {
+	tests := []struct {
+		name     string
+		frame    []byte
+		signal   nibs.Signal
+		expected float64
+	}{
+		{
+			name:     "EngineSpeed : 24|16@1+ (0.125,0)",
+			frame:    []byte{0, 0, 0, 0x40, 0x1F, 0, 0, 0},
+			signal:   nibs.Signal{StartBit: 24, Length: 16, Scale: 0.125},
+			expected: 1000,
+		},
+		{
+			name:     "Temp : 8|12@1- (0.1,-40)",
+			frame:    []byte{0, 0xFB, 0x0F, 0, 0, 0, 0, 0},
+			signal:   nibs.Signal{StartBit: 8, Length: 12, Signed: true, Scale: 0.1, Offset: -40},
+			expected: -40.5,
+		},
+		{
+			name:     "Word : 7|16@0+ (1,0)",
+			frame:    []byte{0x12, 0x34, 0, 0, 0, 0, 0, 0},
+			signal:   nibs.Signal{StartBit: 7, Length: 16, BigEndian: true, Scale: 1},
+			expected: 0x1234,
+		},
+		{
+			name:     "Nibbles : 3|12@0+ (1,0)",
+			frame:    []byte{0xA5, 0xBC, 0, 0, 0, 0, 0, 0},
+			signal:   nibs.Signal{StartBit: 3, Length: 12, BigEndian: true},
+			expected: 0x5BC,
+		},
+		{
+			name:     "Sawtooth : 12|10@0+ (1,0)",
+			frame:    []byte{0, 0x16, 0xC8, 0, 0, 0, 0, 0},
+			signal:   nibs.Signal{StartBit: 12, Length: 10, BigEndian: true},
+			expected: 729,
+		},
+		{
+			name:     "Delta : 23|8@0- (0.5,1)",
+			frame:    []byte{0, 0, 0xFE, 0, 0, 0, 0, 0},
+			signal:   nibs.Signal{StartBit: 23, Length: 8, BigEndian: true, Signed: true, Scale: 0.5, Offset: 1},
+			expected: 0,
+		},
+		{
+			name:     "Flag : 63|1@1+ (1,0)",
+			frame:    []byte{0, 0, 0, 0, 0, 0, 0, 0x80},
+			signal:   nibs.Signal{StartBit: 63, Length: 1},
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		v, err := nibs.DecodeSignal(tt.frame, tt.signal)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.name, err)
+			continue
+		}
+		if math.Abs(v-tt.expected) > 1e-9 {
+			t.Errorf("expected %v for %s, got %v", tt.expected, tt.name, v)
+		}
+	}
+}
+
+func TestDecodeSignals(t *testing.T) {
+	// CAN FD frame with signals in the last bytes
+	frame := make([]byte, 64)
+	frame[62], frame[63] = 0x01, 0x02
+
+	signals := []nibs.Signal{
+		{StartBit: 496, Length: 16},                  // Intel: 0x0201
+		{StartBit: 503, Length: 16, BigEndian: true}, // Motorola: 0x0102
+	}
+	vals, err := nibs.DecodeSignals(frame, signals)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if vals[0] != 0x0201 || vals[1] != 0x0102 {
+		t.Errorf("expected [513 258], got %v", vals)
+	}
+}
+
+func TestDecodeSignalErrors(t *testing.T) {
+	frame := make([]byte, 8)
+
+	tests := []nibs.Signal{
+		{StartBit: 60, Length: 8},
+		{StartBit: -1, Length: 8},
+		{StartBit: 63, Length: 16, BigEndian: true},
+	}
+	for _, s := range tests {
+		if _, err := nibs.DecodeSignal(frame, s); !errors.Is(err, nibs.ErrSignalRange) {
+			t.Errorf("expected error wrapping `nibs.ErrSignalRange` for %+v, got `%v`", s, err)
+		}
+	}
+
+	if _, err := nibs.DecodeSignal(frame, nibs.Signal{Length: 0}); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+
+	_, err := nibs.DecodeSignals(frame, []nibs.Signal{{Length: 8}, {StartBit: 60, Length: 8}})
+	if !errors.Is(err, nibs.ErrSignalRange) {
+		t.Errorf("expected error wrapping `nibs.ErrSignalRange`, got `%v`", err)
+	}
+}
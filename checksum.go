@@ -0,0 +1,212 @@
+package nibs
+
+import (
+	"fmt"
+	"hash"
+)
+
+// ErrChecksumMismatch is the error used when a checksum read from the stream
+// does not match the checksum computed over the data.
+var ErrChecksumMismatch = newSentinel("checksum mismatch")
+
+// ErrNoFletcher16 is the error returned by `VerifyFletcher16` when the option
+// `WithFletcher16` is not used.
+var ErrNoFletcher16 = newSentinel("VerifyFletcher16 requires the WithFletcher16 option")
+
+// WithFletcher16 returns an option that keeps a running Fletcher-16 checksum,
+// as defined in RFC 1146, over the bytes consumed from the stream. See
+// `Fletcher16` and `VerifyFletcher16`.
+func WithFletcher16() Option {
+	return func(n *Nibs) {
+		n.fletcher = true
+	}
+}
+
+// WithBitChecksum returns an option that calls `fn` with each bit consumed
+// from the stream, 0 or 1, in stream order, for checksums computed over bit
+// fields rather than bytes, such as a bitwise LFSR CRC. Bits skipped by `Skip`
+// or `Align` are included, and so are the bits of a read that returns a
+// *PaddedError, but not the zero padding.
+//
+// Reads within `Measure`, and so `Peek`, do not call `fn`, since their bits
+// are not consumed. Bits read again after `Rewind` or `RetryField` are passed
+// to `fn` again, so the caller should reset its checksum to match.
+//
+// `fn` is called once per bit, which makes every read several times slower;
+// use `WithFletcher16` or a hash over whole bytes where the format allows.
+// `Skip` reads the bits it skips rather than seeking.
+func WithBitChecksum(fn func(bit uint8)) Option {
+	return func(n *Nibs) {
+		n.bitChecksum = fn
+	}
+}
+
+// Fletcher16 returns the Fletcher-16 checksum of the bytes consumed so far,
+// with the sum of sums in the high byte. A byte is consumed once its last bit
+// has been read. Zero is returned unless the option `WithFletcher16` is used.
+func (n *Nibs) Fletcher16() uint16 {
+	if !n.fletcher {
+		return 0
+	}
+	n.sumFletcher()
+	return n.fletcherB<<8 | n.fletcherA
+}
+
+// VerifyFletcher16 reads a 16-bit Fletcher-16 checksum from the stream and
+// compares it with the checksum of the bytes consumed before it, as returned
+// by `Fletcher16`. The option `WithFletcher16` must be used, otherwise
+// ErrNoFletcher16 is returned, and the stream must be byte aligned, otherwise
+// an error wrapping ErrMisaligned is returned.
+//
+// An error wrapping ErrChecksumMismatch, and stating both checksums, is
+// returned if they differ.
+func (n *Nibs) VerifyFletcher16() error {
+	if !n.fletcher {
+		return n.wrap(ErrNoFletcher16)
+	}
+	if off := n.offset(); off%8 != 0 {
+		return n.wrap(fmt.Errorf("%w: offset %d is not a multiple of 8 bits", ErrMisaligned, off))
+	}
+	computed := n.Fletcher16()
+	stored, err := n.Nibble16(16)
+	if err != nil {
+		return unexpected(err)
+	}
+	if stored != computed {
+		return n.wrap(fmt.Errorf("%w: computed %#04x, stream has %#04x", ErrChecksumMismatch, computed, stored))
+	}
+	return nil
+}
+
+// sumFletcher adds the bytes consumed since the last call to the running
+// Fletcher-16 checksum.
+func (n *Nibs) sumFletcher() {
+	for ; n.fletcherSummed < n.pos/8; n.fletcherSummed++ {
+		n.fletcherA = uint16((uint32(n.fletcherA) + uint32(n.buf[n.fletcherSummed])) % 255)
+		n.fletcherB = uint16((uint32(n.fletcherB) + uint32(n.fletcherA)) % 255)
+	}
+}
+
+// Checksum1071 reads `nbits` bits from the stream and returns their 16-bit
+// ones' complement sum, as used by the IPv4, TCP and UDP checksums defined in
+// RFC 1071. The stream need not be byte aligned.
+//
+// `nbits` must be a non-negative multiple of 8, otherwise nibs.ErrNibbleSize
+// is returned. If `nbits` is an odd number of bytes the last byte is padded
+// on the right with a zero byte, as described in RFC 1071.
+//
+// The value stored in a checksum field is the ones' complement of the sum
+// (^sum) computed with the field set to zero. Summing a span that includes a
+// valid checksum field yields 0xFFFF.
+//
+// io.ErrUnexpectedEOF is returned if the stream ends before `nbits` bits are read.
+func (n *Nibs) Checksum1071(nbits int64) (checksum uint16, err error) {
+	defer n.field(&err)()
+
+	if nbits < 0 || nbits%8 != 0 {
+		return 0, n.misuseError("Checksum1071", fmt.Sprintf("%d bits; must be a non-negative multiple of 8", nbits))
+	}
+
+	var sum uint64
+	var read int64
+	for read < nbits {
+		size := 64
+		if left := nbits - read; left < 64 {
+			size = int(left)
+		}
+		v, err := n.Nibble(size)
+		if err != nil {
+			return 0, unexpected(err)
+		}
+		read += int64(size)
+
+		// left align a partial read so an odd trailing byte is zero padded
+		v <<= uint(64 - size)
+		sum += v>>48 + v>>32&0xFFFF + v>>16&0xFFFF + v&0xFFFF
+	}
+
+	for sum>>16 != 0 {
+		sum = sum&0xFFFF + sum>>16
+	}
+	return uint16(sum), nil
+}
+
+// NibblePayloadXOR reads a payload of `payloadBytes` bytes followed by a
+// one byte checksum that is the XOR of the payload bytes, as used by NMEA
+// 0183 and many serial protocols, and returns the payload. The stream need
+// not be byte aligned.
+//
+// A negative `payloadBytes` returns nibs.ErrNibbleSize. An error wrapping
+// ErrChecksumMismatch, and stating both checksums, is returned if they differ.
+// io.EOF is returned if the stream is exhausted before the payload, and
+// io.ErrUnexpectedEOF if it ends part way through the payload or checksum.
+func (n *Nibs) NibblePayloadXOR(payloadBytes int) (payload []byte, err error) {
+	defer n.field(&err)()
+
+	if payloadBytes < 0 {
+		return nil, n.misuseError("NibblePayloadXOR", fmt.Sprintf("%d bytes; must not be negative", payloadBytes))
+	}
+
+	payload = make([]byte, payloadBytes)
+	var sum byte
+	for i := range payload {
+		b, err := n.Nibble8(8)
+		if err != nil {
+			if i > 0 {
+				err = unexpected(err)
+			}
+			return nil, err
+		}
+		payload[i] = b
+		sum ^= b
+	}
+
+	stored, err := n.Nibble8(8)
+	if err != nil {
+		if payloadBytes > 0 {
+			err = unexpected(err)
+		}
+		return nil, err
+	}
+	if stored != sum {
+		return nil, n.wrap(fmt.Errorf("%w: computed %#02x, stream has %#02x", ErrChecksumMismatch, sum, stored))
+	}
+	return payload, nil
+}
+
+// ReadPacket reads a packet made of a `lengthBits` length in bytes, that many
+// bytes of payload, then a 32-bit checksum of the payload in network byte
+// order, and returns the payload. `crc` is reset, then fed the payload, and
+// its sum is compared with the checksum read, so crc32.NewIEEE() suits the
+// common case. The stream need not be byte aligned.
+//
+// An error wrapping ErrChecksumMismatch, and stating both checksums, is
+// returned if they differ. io.EOF is returned if the stream is exhausted
+// before the length, and io.ErrUnexpectedEOF if it ends part way through the
+// packet, including when the length is larger than the stream can hold.
+// `lengthBits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned. A length that would allocate more than
+// allowed by `WithMaxAlloc` returns an error wrapping ErrAllocLimit.
+func (n *Nibs) ReadPacket(lengthBits int, crc hash.Hash32) (payload []byte, err error) {
+	defer n.field(&err)()
+
+	length, err := n.Nibble(lengthBits)
+	if err != nil {
+		return nil, err
+	}
+	payload, err = n.nibbleBytes(length)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := n.Nibble32(32)
+	if err != nil {
+		return nil, unexpected(err)
+	}
+	crc.Reset()
+	crc.Write(payload)
+	if computed := crc.Sum32(); stored != computed {
+		return nil, n.wrap(fmt.Errorf("%w: computed %#08x, stream has %#08x", ErrChecksumMismatch, computed, stored))
+	}
+	return payload, nil
+}
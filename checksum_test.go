@@ -0,0 +1,317 @@
+package nibs_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"hash/crc32"
+	"io"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestChecksum1071(t *testing.T) {
+	// IPv4 header with checksum 0xb861
+	header := mustHex("450000730000400040110000c0a80001c0a800c7")
+
+	nib := nibs.New(bytes.NewReader(header))
+	sum, err := nib.Checksum1071(int64(len(header)) * 8)
+	if err != nil || ^sum != 0xB861 {
+		t.Errorf("expected checksum 0xb861, got %#x and error `%v`", ^sum, err)
+	}
+
+	// including a valid checksum sums to 0xffff
+	header[10], header[11] = 0xB8, 0x61
+	nib = nibs.New(bytes.NewReader(shifted(header, 3)))
+	if _, err := nib.Nibble(3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sum, err = nib.Checksum1071(int64(len(header)) * 8)
+	if err != nil || sum != 0xFFFF {
+		t.Errorf("expected sum 0xffff, got %#x and error `%v`", sum, err)
+	}
+}
+
+func TestChecksum1071OddLength(t *testing.T) {
+	// RFC 1071 example bytes, with a trailing odd byte
+	b := mustHex("0001f203f4f5f6f7ab")
+	nib := nibs.New(bytes.NewReader(b))
+	sum, err := nib.Checksum1071(int64(len(b)) * 8)
+	// 0x0001 + 0xf203 + 0xf4f5 + 0xf6f7 = 0x2ddf0 -> 0xddf2, plus 0xab00
+	if err != nil || sum != 0x88F3 {
+		t.Errorf("expected sum 0x88f3, got %#x and error `%v`", sum, err)
+	}
+}
+
+func TestChecksum1071Errors(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(make([]byte, 4)))
+	if _, err := nib.Checksum1071(12); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, err := nib.Checksum1071(128); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+}
+
+// fletcher16 is a reference implementation of the Fletcher-16 checksum.
+func fletcher16(b []byte) uint16 {
+	var a, s int
+	for _, c := range b {
+		a = (a + int(c)) % 255
+		s = (s + a) % 255
+	}
+	return uint16(s<<8 | a)
+}
+
+func TestFletcher16(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected uint16
+	}{
+		{data: "abcde", expected: 0xC8F0},
+		{data: "abcdef", expected: 0x2057},
+		{data: "abcdefgh", expected: 0x0627},
+	}
+
+	for _, tt := range tests {
+		nib := nibs.New(bytes.NewReader([]byte(tt.data)), nibs.WithFletcher16())
+		for range tt.data {
+			if _, err := nib.Nibble(8); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if sum := nib.Fletcher16(); sum != tt.expected {
+			t.Errorf("expected %#04x for %q, got %#04x", tt.expected, tt.data, sum)
+		}
+	}
+
+	// partially consumed bytes are not summed
+	nib := nibs.New(bytes.NewReader([]byte("abcde")), nibs.WithFletcher16())
+	if _, err := nib.Nibble(20); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sum := nib.Fletcher16(); sum != fletcher16([]byte("ab")) {
+		t.Errorf("expected %#04x, got %#04x", fletcher16([]byte("ab")), sum)
+	}
+
+	// without the option
+	nib = nibs.New(bytes.NewReader([]byte("abcde")))
+	if _, err := nib.Nibble(40); err != nil || nib.Fletcher16() != 0 {
+		t.Errorf("expected zero checksum without option, got %#04x and error `%v`", nib.Fletcher16(), err)
+	}
+}
+
+func TestVerifyFletcher16(t *testing.T) {
+	payload := make([]byte, 1000)
+	for i := range payload {
+		payload[i] = byte(i * 7)
+	}
+	sum := fletcher16(payload)
+	b := append(append([]byte{}, payload...), byte(sum>>8), byte(sum))
+
+	// read in odd sizes so the sum spans buffer refills and unaligned reads
+	nib := nibs.New(bytes.NewReader(b), nibs.WithFletcher16())
+	for read := 0; read < len(payload)*8; {
+		size := 13
+		if left := len(payload)*8 - read; left < size {
+			size = left
+		}
+		if _, err := nib.Nibble(size); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		read += size
+	}
+	if err := nib.VerifyFletcher16(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// mismatch
+	b[10] ^= 0x01
+	nib = nibs.New(bytes.NewReader(b), nibs.WithFletcher16())
+	if err := nib.Skip(int64(len(payload)) * 8); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := nib.VerifyFletcher16(); !errors.Is(err, nibs.ErrChecksumMismatch) {
+		t.Errorf("expected `nibs.ErrChecksumMismatch`, got `%v`", err)
+	}
+
+	// truncated checksum
+	nib = nibs.New(bytes.NewReader(b[:len(payload)+1]), nibs.WithFletcher16())
+	if err := nib.Skip(int64(len(payload)) * 8); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := nib.VerifyFletcher16(); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+
+	// misuse
+	nib = nibs.New(bytes.NewReader(b))
+	if err := nib.VerifyFletcher16(); err == nil {
+		t.Error("expected error without option")
+	}
+	nib = nibs.New(bytes.NewReader(b), nibs.WithFletcher16())
+	nib.Nibble(3)
+	if err := nib.VerifyFletcher16(); err == nil {
+		t.Error("expected error when unaligned")
+	}
+}
+
+func TestNibblePayloadXOR(t *testing.T) {
+	payload := []byte("GPGLL,5300.97914,N")
+	var sum byte
+	for _, b := range payload {
+		sum ^= b
+	}
+	b := append(append([]byte{}, payload...), sum)
+
+	// unaligned by 5 bits
+	nib := nibs.New(bytes.NewReader(shifted(b, 5)))
+	if _, err := nib.Nibble(5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := nib.NibblePayloadXOR(len(payload))
+	if err != nil || !bytes.Equal(got, payload) {
+		t.Errorf("expected %q, got %q and error `%v`", payload, got, err)
+	}
+
+	// mismatch
+	b[len(b)-1] ^= 0x20
+	nib = nibs.New(bytes.NewReader(b))
+	if got, err := nib.NibblePayloadXOR(len(payload)); !errors.Is(err, nibs.ErrChecksumMismatch) || got != nil {
+		t.Errorf("expected `nibs.ErrChecksumMismatch`, got %q and error `%v`", got, err)
+	}
+
+	// truncated
+	nib = nibs.New(bytes.NewReader(b[:len(payload)]))
+	if _, err := nib.NibblePayloadXOR(len(payload)); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+	if _, err := nib.NibblePayloadXOR(len(payload)); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+
+	if _, err := nib.NibblePayloadXOR(-1); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
+
+// packet returns `payload` framed by a 16-bit length and a CRC-32.
+func packet(payload []byte) []byte {
+	b := binary.BigEndian.AppendUint16(nil, uint16(len(payload)))
+	b = append(b, payload...)
+	return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(payload))
+}
+
+func TestReadPacket(t *testing.T) {
+	payloads := [][]byte{[]byte("hello, world"), {}, bytes.Repeat([]byte{0xA5}, 300)}
+	var b []byte
+	for _, p := range payloads {
+		b = append(b, packet(p)...)
+	}
+
+	crc := crc32.NewIEEE()
+	nib := nibs.New(bytes.NewReader(shifted(b, 3)))
+	if _, err := nib.Nibble(3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range payloads {
+		got, err := nib.ReadPacket(16, crc)
+		if err != nil || !bytes.Equal(got, expected) {
+			t.Errorf("expected %q, got %q and error `%v`", expected, got, err)
+		}
+	}
+	if _, err := nib.ReadPacket(16, crc); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestReadPacketHugeLength(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(bytes.Repeat([]byte{0xFF}, 8)))
+	if _, err := nib.ReadPacket(64, crc32.NewIEEE()); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+
+	// a 4 GiB length before the end of the stream is known
+	b := make([]byte, 64<<10)
+	copy(b, []byte{0xFF, 0xFF, 0xFF, 0xFF})
+	nib = nibs.New(bytes.NewReader(b))
+	if _, err := nib.ReadPacket(32, crc32.NewIEEE()); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+}
+
+func TestReadPacketErrors(t *testing.T) {
+	b := packet([]byte("hello, world"))
+	crc := crc32.NewIEEE()
+
+	// truncated in the payload and in the checksum
+	for _, size := range []int{3, 8, len(b) - 1} {
+		nib := nibs.New(bytes.NewReader(b[:size]))
+		if _, err := nib.ReadPacket(16, crc); err != io.ErrUnexpectedEOF {
+			t.Errorf("expected `io.ErrUnexpectedEOF` for %d bytes, got `%v`", size, err)
+		}
+	}
+
+	// corrupt payload
+	b[5] ^= 0x01
+	nib := nibs.New(bytes.NewReader(b))
+	if got, err := nib.ReadPacket(16, crc); !errors.Is(err, nibs.ErrChecksumMismatch) || got != nil {
+		t.Errorf("expected `nibs.ErrChecksumMismatch`, got %q and error `%v`", got, err)
+	}
+
+	nib = nibs.New(bytes.NewReader(b), nibs.WithMaxAlloc(8))
+	if _, err := nib.ReadPacket(16, crc); !errors.Is(err, nibs.ErrAllocLimit) {
+		t.Errorf("expected `nibs.ErrAllocLimit`, got `%v`", err)
+	}
+}
+
+func TestWithBitChecksum(t *testing.T) {
+	data := []byte{0xB4, 0x7E, 0x01, 0xC3}
+	var parity, count uint8
+	nib := nibs.New(bytes.NewReader(data), nibs.WithBitChecksum(func(bit uint8) {
+		parity ^= bit
+		count++
+	}))
+
+	// a 13-bit field 1011 0100 0111 1 has eight 1 bits
+	if _, err := nib.Nibble(13); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parity != 0 || count != 13 {
+		t.Errorf("expected even parity over 13 bits, got %d over %d", parity, count)
+	}
+
+	// peeked bits are not consumed; skipped bits are
+	if _, err := nib.Peek(8); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 13 {
+		t.Errorf("expected 13 bits after peek, got %d", count)
+	}
+	if err := nib.Skip(11); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := nib.NibbleTable(nibs.NewDecodeTable(8)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var expected uint8
+	for _, b := range data {
+		for ; b != 0; b &= b - 1 {
+			expected ^= 1
+		}
+	}
+	if parity != expected || count != 32 {
+		t.Errorf("expected parity %d over 32 bits, got %d over %d", expected, parity, count)
+	}
+}
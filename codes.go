@@ -0,0 +1,296 @@
+package nibs
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// ErrVarintTooLong is the error used when a varint is not terminated within
+// the maximum number of bytes allowed.
+var ErrVarintTooLong = newSentinel("varint too long")
+
+// ErrUnaryTooLong is the error used when a run of identical bits within a code
+// is longer than allowed by the option `WithMaxUnaryRun`.
+var ErrUnaryTooLong = newSentinel("unary run too long")
+
+// NibbleFibonacci reads a Fibonacci coded integer from the bit stream.
+//
+// Each bit, starting with the first read, carries the weight of the next
+// Fibonacci number (1, 2, 3, 5, 8, ...) and the code is terminated by two
+// consecutive 1 bits, which are consumed. The smallest encodable value is 1.
+//
+// io.EOF is returned if the stream is exhausted before the first bit of the
+// code, and io.ErrUnexpectedEOF if it ends part way through the code.
+// ErrOverflow is returned if the value does not fit in a uint64. An error
+// wrapping ErrUnaryTooLong is returned for a run of 0 bits longer than allowed
+// by `WithMaxUnaryRun`.
+func (n *Nibs) NibbleFibonacci() (value uint64, err error) {
+	defer n.field(&err)()
+
+	var val uint64
+	var prev uint64
+	var carry uint64
+	var zeros int
+
+	// weight of the current bit and the next bit, and whether each has overflowed
+	weight, next := uint64(1), uint64(2)
+	weightOver, nextOver := false, false
+
+	for i := 0; ; i++ {
+		bit, err := n.Nibble(1)
+		if err != nil {
+			if i > 0 {
+				return 0, unexpected(err)
+			}
+			return 0, err
+		}
+
+		if bit == 1 {
+			if prev == 1 {
+				return val, nil
+			}
+			if weightOver {
+				return 0, n.wrap(ErrOverflow)
+			}
+			if val, carry = bits.Add64(val, weight, 0); carry != 0 {
+				return 0, n.wrap(ErrOverflow)
+			}
+			zeros = 0
+		} else {
+			if zeros++; n.runTooLong(zeros) {
+				return 0, n.runError(zeros)
+			}
+		}
+		prev = bit
+
+		sum, c := bits.Add64(weight, next, 0)
+		weight, next = next, sum
+		weightOver = nextOver
+		nextOver = nextOver || c != 0
+	}
+}
+
+// NibbleVarintMax reads an unsigned LEB128 varint, as used by protocol buffers
+// and encoding/binary, consisting of 8-bit groups holding 7 data bits each,
+// least significant group first, with the high bit set on every group except
+// the last. The groups need not be byte aligned.
+//
+// ErrVarintTooLong is returned if `maxBytes` groups are read without finding
+// the last group, which guards against unbounded reads of untrusted input.
+// ErrOverflow is returned if the value does not fit in a uint64.
+//
+// io.EOF is returned if the stream is exhausted before the first group, and
+// io.ErrUnexpectedEOF if it ends part way through the varint.
+func (n *Nibs) NibbleVarintMax(maxBytes int) (value uint64, err error) {
+	defer n.field(&err)()
+
+	var val uint64
+	for i := 0; i < maxBytes; i++ {
+		group, err := n.Nibble(8)
+		if err != nil {
+			if i > 0 {
+				return 0, unexpected(err)
+			}
+			return 0, err
+		}
+
+		data := group & 0x7F
+		if i == 9 && data > 1 || i > 9 && data != 0 {
+			return 0, n.wrap(ErrOverflow)
+		}
+		if i < 10 {
+			val |= data << uint(7*i)
+		}
+		if group&0x80 == 0 {
+			return val, nil
+		}
+	}
+	return 0, n.wrap(ErrVarintTooLong)
+}
+
+// maxContinuationBytes is the most groups `NibbleContinuationBytes` reads
+// without finding the last group.
+const maxContinuationBytes = 1 << 16
+
+// NibbleContinuationBytes reads a sequence of 8-bit groups holding 7 data bits
+// each, with the high bit set on every group except the last, as for
+// `NibbleVarintMax` but of any length, and returns the 7 data bits of each
+// group as a byte, in stream order. The groups need not be byte aligned.
+//
+// An error wrapping ErrVarintTooLong is returned if 65536 groups are read
+// without finding the last group, and one wrapping ErrAllocLimit if the groups
+// would allocate more than allowed by `WithMaxAlloc`. The groups read are
+// consumed in either case.
+//
+// io.EOF is returned if the stream is exhausted before the first group, and
+// io.ErrUnexpectedEOF if it ends part way through the sequence.
+func (n *Nibs) NibbleContinuationBytes() (data []byte, err error) {
+	defer n.field(&err)()
+
+	var groups []byte
+	for len(groups) < maxContinuationBytes {
+		if err := n.checkAlloc(uint64(len(groups)+1), 1); err != nil {
+			return nil, err
+		}
+		group, err := n.Nibble(8)
+		if err != nil {
+			if len(groups) > 0 {
+				err = unexpected(err)
+			}
+			return nil, err
+		}
+		groups = append(groups, byte(group&0x7F))
+		if group&0x80 == 0 {
+			return groups, nil
+		}
+	}
+	return nil, n.wrap(fmt.Errorf("%w: no last group in %d bytes", ErrVarintTooLong, maxContinuationBytes))
+}
+
+// NibbleLevenshtein reads a Levenshtein coded integer from the bit stream.
+//
+// The code starts with a count C of 1 bits terminated by a 0 bit. C of zero
+// is the value 0. Otherwise, starting from N = 1, C-1 fields are read, each
+// of N bits, and N becomes the field value with a 1 bit prepended. The final
+// N is the value.
+//
+// io.EOF is returned if the stream is exhausted before the first bit of the
+// code, and io.ErrUnexpectedEOF if it ends part way through the code.
+// ErrOverflow is returned if the value does not fit in a uint64. An error
+// wrapping ErrUnaryTooLong is returned for a count longer than allowed by
+// `WithMaxUnaryRun`.
+func (n *Nibs) NibbleLevenshtein() (value uint64, err error) {
+	defer n.field(&err)()
+
+	var count int
+	for {
+		bit, err := n.Nibble(1)
+		if err != nil {
+			if count > 0 {
+				return 0, unexpected(err)
+			}
+			return 0, err
+		}
+		if bit == 0 {
+			break
+		}
+		if count++; n.runTooLong(count) {
+			return 0, n.runError(count)
+		}
+		// six or more steps needs a field of at least 65536 bits
+		if count > 5 {
+			return 0, n.wrap(ErrOverflow)
+		}
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	val := uint64(1)
+	for i := 1; i < count; i++ {
+		if val > 63 {
+			return 0, n.wrap(ErrOverflow)
+		}
+		field, err := n.Nibble(int(val))
+		if err != nil {
+			return 0, unexpected(err)
+		}
+		val = 1<<val | field
+	}
+	return val, nil
+}
+
+// NibbleExpGolombUnsigned reads an order 0 exponential-Golomb coded integer,
+// the ue(v) syntax element of H.264 and HEVC, from the bit stream.
+//
+// The code is a count Z of 0 bits, a 1 bit, then Z info bits. The value is
+// 2^Z - 1 plus the info bits, so 1 is 0, 010 is 1, 011 is 2 and 00100 is 3.
+//
+// io.EOF is returned if the stream is exhausted before the first bit of the
+// code, and io.ErrUnexpectedEOF if it ends part way through the code.
+// ErrOverflow is returned if the value does not fit in a uint64. An error
+// wrapping ErrUnaryTooLong is returned for a count Z longer than allowed by
+// `WithMaxUnaryRun`.
+func (n *Nibs) NibbleExpGolombUnsigned() (value uint64, err error) {
+	defer n.field(&err)()
+
+	var zeros int
+	for {
+		bit, err := n.Nibble(1)
+		if err != nil {
+			if zeros > 0 {
+				return 0, unexpected(err)
+			}
+			return 0, err
+		}
+		if bit == 1 {
+			break
+		}
+		if zeros++; n.runTooLong(zeros) {
+			return 0, n.runError(zeros)
+		}
+		if zeros > 64 {
+			return 0, n.wrap(ErrOverflow)
+		}
+	}
+	if zeros == 0 {
+		return 0, nil
+	}
+
+	info, err := n.Nibble(zeros)
+	if err != nil {
+		return 0, unexpected(err)
+	}
+	val, carry := bits.Add64(^uint64(0)>>uint(64-zeros), info, 0)
+	if carry != 0 {
+		return 0, n.wrap(ErrOverflow)
+	}
+	return val, nil
+}
+
+// NibbleExpGolombSigned reads an order 0 exponential-Golomb coded signed
+// integer, the se(v) syntax element of H.264 and HEVC, from the bit stream.
+//
+// The code is read as for `NibbleExpGolombUnsigned` and the code number K is
+// mapped to (K+1)/2 when odd and -K/2 when even, so 0, 1, 2, 3, 4 are 0, 1,
+// -1, 2, -2.
+//
+// Errors are as for `NibbleExpGolombUnsigned`. ErrOverflow is also returned
+// if the value does not fit in an int64.
+func (n *Nibs) NibbleExpGolombSigned() (int64, error) {
+	k, err := n.NibbleExpGolombUnsigned()
+	if err != nil {
+		return 0, err
+	}
+	if k%2 == 0 {
+		return -int64(k / 2), nil
+	}
+	if k/2 >= 1<<63-1 {
+		return 0, n.wrap(ErrOverflow)
+	}
+	return int64(k/2 + 1), nil
+}
+
+// runTooLong reports whether a run of `run` identical bits within a code
+// exceeds the limit set by `WithMaxUnaryRun`, if any.
+func (n *Nibs) runTooLong(run int) bool {
+	return n.maxUnaryRun > 0 && run > n.maxUnaryRun
+}
+
+// runError returns an error wrapping ErrUnaryTooLong for a run of `run` bits.
+func (n *Nibs) runError(run int) error {
+	return n.wrap(fmt.Errorf("%w: run of %d bits, limit %d", ErrUnaryTooLong, run, n.maxUnaryRun))
+}
+
+// unexpected converts an io.EOF encountered part way through a multi-part
+// field into io.ErrUnexpectedEOF.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if fe, ok := err.(*FieldError); ok && fe.Err == io.EOF {
+		return &FieldError{Path: fe.Path, Offset: fe.Offset, Err: io.ErrUnexpectedEOF}
+	}
+	return err
+}
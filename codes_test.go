@@ -0,0 +1,293 @@
+package nibs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/bits"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+var fibonacciCodes = []string{
+	"11", "011", "0011", "1011", "00011",
+	"10011", "01011", "000011", "100011", "010011",
+	"001011", "101011", "0000011", "1000011", "0100011",
+	"0010011", "1010011", "0001011", "1001011", "0101011",
+}
+
+func TestNibbleFibonacci(t *testing.T) {
+	b := PackBits(strings.Join(fibonacciCodes, " "))
+	nib := nibs.New(bytes.NewReader(b))
+
+	for i := range fibonacciCodes {
+		expected := uint64(i + 1)
+		n, err := nib.NibbleFibonacci()
+		if err != nil {
+			t.Errorf("unexpected error decoding %d: %v", expected, err)
+			return
+		}
+		if n != expected {
+			t.Errorf("expected %d, got %d", expected, n)
+		}
+	}
+}
+
+func TestNibbleFibonacciTruncated(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(PackBits("01010101")))
+	if _, err := nib.NibbleFibonacci(); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+
+	nib = nibs.New(bytes.NewReader(nil))
+	if _, err := nib.NibbleFibonacci(); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestNibbleFibonacciOverflow(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(PackBits(strings.Repeat("0", 100) + "11")))
+	if _, err := nib.NibbleFibonacci(); err != nibs.ErrOverflow {
+		t.Errorf("expected `nibs.ErrOverflow`, got `%v`", err)
+	}
+}
+
+func TestNibbleVarintMax(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		maxBytes int
+		expected uint64
+		err      error
+	}{
+		{name: "one byte", data: []byte{0x01}, maxBytes: 1, expected: 1},
+		{name: "three bytes", data: []byte{0xA0, 0x8D, 0x06}, maxBytes: 5, expected: 100000},
+		{name: "max uint64", data: append(bytes.Repeat([]byte{0xFF}, 9), 0x01), maxBytes: 10, expected: 1<<64 - 1},
+		{name: "too long", data: bytes.Repeat([]byte{0x80}, 6), maxBytes: 4, err: nibs.ErrVarintTooLong},
+		{name: "overflow", data: append(bytes.Repeat([]byte{0xFF}, 9), 0x02), maxBytes: 10, err: nibs.ErrOverflow},
+		{name: "truncated", data: []byte{0x80, 0x80}, maxBytes: 5, err: io.ErrUnexpectedEOF},
+		{name: "empty", data: nil, maxBytes: 5, err: io.EOF},
+	}
+
+	for _, tt := range tests {
+		nib := nibs.New(bytes.NewReader(tt.data))
+		v, err := nib.NibbleVarintMax(tt.maxBytes)
+		if err != tt.err {
+			t.Errorf("expected error `%v` for %s, got `%v`", tt.err, tt.name, err)
+			continue
+		}
+		if v != tt.expected {
+			t.Errorf("expected %d for %s, got %d", tt.expected, tt.name, v)
+		}
+	}
+}
+
+func TestNibbleVarintMaxUnaligned(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(PackBits("11 10101100 00000010 000000")))
+	if _, err := nib.Nibble(2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	v, err := nib.NibbleVarintMax(2)
+	if err != nil || v != 300 {
+		t.Errorf("expected 300, got %d and error `%v`", v, err)
+	}
+}
+
+// levenshteinCode returns the Levenshtein code of `v` as a string of bits.
+func levenshteinCode(v uint64) string {
+	if v == 0 {
+		return "0"
+	}
+	count := 1
+	code := ""
+	for {
+		// prepend the bits of v after its leading 1, then encode their number
+		code = strconv.FormatUint(v, 2)[1:] + code
+		width := bits.Len64(v) - 1
+		if width == 0 {
+			break
+		}
+		v = uint64(width)
+		count++
+	}
+	return strings.Repeat("1", count) + "0" + code
+}
+
+func TestNibbleContinuationBytes(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0x81, 0xFF, 0x05, 0x7F}))
+
+	groups, err := nib.NibbleContinuationBytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(groups, []byte{0x01, 0x7F, 0x05}) {
+		t.Errorf("expected [01 7f 05], got % x", groups)
+	}
+	groups, err = nib.NibbleContinuationBytes()
+	if err != nil || !bytes.Equal(groups, []byte{0x7F}) {
+		t.Errorf("expected [7f], got % x and error `%v`", groups, err)
+	}
+	if _, err := nib.NibbleContinuationBytes(); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+
+	nib = nibs.New(bytes.NewReader([]byte{0x81, 0x82}))
+	if _, err := nib.NibbleContinuationBytes(); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+
+	nib = nibs.New(bytes.NewReader(bytes.Repeat([]byte{0x80}, 70000)))
+	if _, err := nib.NibbleContinuationBytes(); !errors.Is(err, nibs.ErrVarintTooLong) {
+		t.Errorf("expected `nibs.ErrVarintTooLong`, got `%v`", err)
+	}
+
+	nib = nibs.New(bytes.NewReader([]byte{0x81, 0x82, 0x03}), nibs.WithMaxAlloc(2))
+	if _, err := nib.NibbleContinuationBytes(); !errors.Is(err, nibs.ErrAllocLimit) {
+		t.Errorf("expected `nibs.ErrAllocLimit`, got `%v`", err)
+	}
+}
+
+func TestNibbleLevenshtein(t *testing.T) {
+	reference := []string{"0", "10", "1100", "1101", "1110000", "1110001", "1110010", "1110011", "11101000"}
+	for v, code := range reference {
+		if got := levenshteinCode(uint64(v)); got != code {
+			t.Fatalf("reference encoder gave %s for %d, expected %s", got, v, code)
+		}
+	}
+
+	values := []uint64{16, 17, 100, 1000, 65535, 65536, 1<<32 + 5, 1<<64 - 1}
+	var codes []string
+	for v := uint64(0); v < 20; v++ {
+		codes = append(codes, levenshteinCode(v))
+	}
+	for _, v := range values {
+		codes = append(codes, levenshteinCode(v))
+	}
+	nib := nibs.New(bytes.NewReader(PackBits(strings.Join(codes, " "))))
+
+	for v := uint64(0); v < 20; v++ {
+		if got, err := nib.NibbleLevenshtein(); err != nil || got != v {
+			t.Errorf("expected %d, got %d and error `%v`", v, got, err)
+		}
+	}
+	for _, v := range values {
+		if got, err := nib.NibbleLevenshtein(); err != nil || got != v {
+			t.Errorf("expected %d, got %d and error `%v`", v, got, err)
+		}
+	}
+}
+
+func TestNibbleLevenshteinErrors(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(nil))
+	if _, err := nib.NibbleLevenshtein(); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+
+	// 1000 cut short after 11101
+	nib = nibs.New(bytes.NewReader(PackBits("11101")))
+	header, _, _ := nib.SplitAt(5)
+	if _, err := header.NibbleLevenshtein(); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+
+	// count of 5 where the last field would be 32768 bits overflows
+	nib = nibs.New(bytes.NewReader(PackBits("111110 1 111 000000000000000")))
+	if _, err := nib.NibbleLevenshtein(); err != nibs.ErrOverflow {
+		t.Errorf("expected `nibs.ErrOverflow`, got `%v`", err)
+	}
+	nib = nibs.New(bytes.NewReader(PackBits("1111110")))
+	if _, err := nib.NibbleLevenshtein(); err != nibs.ErrOverflow {
+		t.Errorf("expected `nibs.ErrOverflow`, got `%v`", err)
+	}
+}
+
+func TestNibbleExpGolombUnsigned(t *testing.T) {
+	codes := []string{"1", "010", "011", "00100", "00101", "00111", "0001000"}
+	nib := nibs.New(bytes.NewReader(PackBits(strings.Join(codes, " "))))
+	for i, expected := range []uint64{0, 1, 2, 3, 4, 6, 7} {
+		if v, err := nib.NibbleExpGolombUnsigned(); err != nil || v != expected {
+			t.Errorf("code %s: expected %d, got %d and error `%v`", codes[i], expected, v, err)
+		}
+	}
+
+	// largest value: 64 zeros, a 1 and 64 zero info bits
+	b := PackBits(strings.Repeat("0", 64) + "1" + strings.Repeat("0", 64))
+	nib = nibs.New(bytes.NewReader(b))
+	if v, err := nib.NibbleExpGolombUnsigned(); err != nil || v != ^uint64(0) {
+		t.Errorf("expected %d, got %d and error `%v`", uint64(^uint64(0)), v, err)
+	}
+}
+
+func TestNibbleExpGolombSigned(t *testing.T) {
+	codes := []string{"1", "010", "011", "00100", "00101"}
+	nib := nibs.New(bytes.NewReader(PackBits(strings.Join(codes, " "))))
+	for i, expected := range []int64{0, 1, -1, 2, -2} {
+		if v, err := nib.NibbleExpGolombSigned(); err != nil || v != expected {
+			t.Errorf("code %s: expected %d, got %d and error `%v`", codes[i], expected, v, err)
+		}
+	}
+
+	// code number 2^64-1 maps to 2^63, one past the largest int64
+	b := PackBits(strings.Repeat("0", 64) + "1" + strings.Repeat("0", 64))
+	nib = nibs.New(bytes.NewReader(b))
+	if _, err := nib.NibbleExpGolombSigned(); err != nibs.ErrOverflow {
+		t.Errorf("expected `nibs.ErrOverflow`, got `%v`", err)
+	}
+}
+
+func TestNibbleExpGolombErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		bits     string
+		expected error
+	}{
+		{name: "empty", bits: "", expected: io.EOF},
+		{name: "zeros only", bits: "000", expected: io.ErrUnexpectedEOF},
+		{name: "short info", bits: "00001 01", expected: io.ErrUnexpectedEOF},
+		{name: "too many zeros", bits: strings.Repeat("0", 65) + "1", expected: nibs.ErrOverflow},
+		{name: "overflow", bits: strings.Repeat("0", 64) + "1" + strings.Repeat("0", 63) + "1", expected: nibs.ErrOverflow},
+	}
+	for _, tt := range tests {
+		nib := nibs.New(bytes.NewReader(PackBits(tt.bits)))
+		if _, err := nib.NibbleExpGolombUnsigned(); err != tt.expected {
+			t.Errorf("%s: expected `%v`, got `%v`", tt.name, tt.expected, err)
+		}
+	}
+}
+
+func TestWithMaxUnaryRun(t *testing.T) {
+	// runs up to the limit decode as usual
+	nib := nibs.New(bytes.NewReader(PackBits("0001001 1011")), nibs.WithMaxUnaryRun(3))
+	if v, err := nib.NibbleExpGolombUnsigned(); err != nil || v != 8 {
+		t.Errorf("expected 8, got %d and error `%v`", v, err)
+	}
+	if v, err := nib.NibbleFibonacci(); err != nil || v != 4 {
+		t.Errorf("expected 4, got %d and error `%v`", v, err)
+	}
+
+	// an all-zeros stream stops at the limit rather than at EOF
+	zeros := make([]byte, 1024)
+	for name, read := range map[string]func(*nibs.Nibs) error{
+		"exp-golomb": func(n *nibs.Nibs) error { _, err := n.NibbleExpGolombUnsigned(); return err },
+		"signed":     func(n *nibs.Nibs) error { _, err := n.NibbleExpGolombSigned(); return err },
+		"fibonacci":  func(n *nibs.Nibs) error { _, err := n.NibbleFibonacci(); return err },
+	} {
+		nib := nibs.New(bytes.NewReader(zeros), nibs.WithMaxUnaryRun(16))
+		if err := read(nib); !errors.Is(err, nibs.ErrUnaryTooLong) {
+			t.Errorf("%s: expected `nibs.ErrUnaryTooLong`, got `%v`", name, err)
+		}
+		if nib.BitsRead() != 17 {
+			t.Errorf("%s: expected to stop after 17 bits, read %d", name, nib.BitsRead())
+		}
+	}
+
+	nib = nibs.New(bytes.NewReader([]byte{0xFF}), nibs.WithMaxUnaryRun(2))
+	if _, err := nib.NibbleLevenshtein(); !errors.Is(err, nibs.ErrUnaryTooLong) {
+		t.Errorf("levenshtein: expected `nibs.ErrUnaryTooLong`, got `%v`", err)
+	}
+}
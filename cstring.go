@@ -0,0 +1,46 @@
+package nibs
+
+import "fmt"
+
+// ErrStringTooLong is the error used when a string read from the stream has
+// no terminator within the maximum length allowed for it.
+var ErrStringTooLong = newSentinel("string too long")
+
+// NibbleCString reads a NUL-terminated string, as stored by C structures, and
+// returns it without the terminator, which is consumed. At most `maxLen`
+// bytes are read, including the terminator. An error wrapping
+// ErrStringTooLong is returned if none of them is a NUL, in which case the
+// bytes are consumed.
+//
+// The stream must be byte aligned, otherwise an error wrapping ErrMisaligned
+// is returned and no bits are consumed. `maxLen` must be at least 1,
+// otherwise nibs.ErrNibbleSize is returned.
+//
+// io.EOF is returned if the stream is exhausted before the first byte, and
+// io.ErrUnexpectedEOF if it ends part way through the string.
+func (n *Nibs) NibbleCString(maxLen int) (str string, err error) {
+	defer n.field(&err)()
+
+	if maxLen < 1 {
+		return "", n.misuseError("NibbleCString", fmt.Sprintf("a maximum length of %d; must be at least 1", maxLen))
+	}
+	if off := n.offset(); off%8 != 0 {
+		return "", n.wrap(fmt.Errorf("%w: offset %d is not a multiple of 8 bits", ErrMisaligned, off))
+	}
+
+	var s []byte
+	for i := 0; i < maxLen; i++ {
+		b, err := n.Nibble8(8)
+		if err != nil {
+			if i > 0 {
+				err = unexpected(err)
+			}
+			return "", err
+		}
+		if b == 0 {
+			return string(s), nil
+		}
+		s = append(s, b)
+	}
+	return "", n.wrap(fmt.Errorf("%w: no terminator in %d bytes", ErrStringTooLong, maxLen))
+}
@@ -0,0 +1,58 @@
+package nibs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestNibbleCString(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte("hello\x00\x00rest")))
+
+	for _, expected := range []string{"hello", ""} {
+		s, err := nib.NibbleCString(16)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if s != expected {
+			t.Errorf("expected %q, got %q", expected, s)
+		}
+	}
+	rest, err := nib.Nibble(32)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rest != 0x72657374 {
+		t.Errorf("expected the bytes after the terminator, got %#x", rest)
+	}
+}
+
+func TestNibbleCStringErrors(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte("hello\x00ab")))
+
+	// the terminator counts towards the maximum
+	if _, err := nib.NibbleCString(5); !errors.Is(err, nibs.ErrStringTooLong) {
+		t.Errorf("expected `nibs.ErrStringTooLong`, got `%v`", err)
+	}
+	if _, err := nib.NibbleCString(0); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, err := nib.Nibble(4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := nib.NibbleCString(8); !errors.Is(err, nibs.ErrMisaligned) {
+		t.Errorf("expected `nibs.ErrMisaligned`, got `%v`", err)
+	}
+	if _, err := nib.Nibble(4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := nib.NibbleCString(8); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+	if _, err := nib.NibbleCString(8); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
@@ -0,0 +1,60 @@
+package nibs
+
+import "math"
+
+// NibbleDeltaInit reads `bits` number of bits from the byte stream as a two's
+// complement value and returns it sign extended, after making it the starting
+// value for `NibbleDeltaZigZag`. This reads the first, absolute, value of a
+// delta coded series.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+func (n *Nibs) NibbleDeltaInit(bits int) (int64, error) {
+	v, err := n.Nibble(bits)
+	if err != nil {
+		return 0, err
+	}
+	n.delta = signExtend(v, bits)
+	return n.delta, nil
+}
+
+// NibbleDeltaZigZag reads a zig-zag coded signed delta of `bits` bits, adds it
+// to the previous value of the series, and returns the sum, as columnar and
+// time series formats store slowly changing values. Zig-zag coding maps 0, -1,
+// 1, -2, 2 to 0, 1, 2, 3, 4, so small steps of either sign need few bits.
+//
+// The series starts from the value read by `NibbleDeltaInit`, or 0 if there
+// was none since the Nibs was created, `Reset` or `Rewind`.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned. ErrOverflow is returned if the sum does not
+// fit in an int64, and the previous value is kept.
+func (n *Nibs) NibbleDeltaZigZag(bits int) (int64, error) {
+	v, err := n.Nibble(bits)
+	if err != nil {
+		return 0, err
+	}
+	d := int64(v>>1) ^ -int64(v&1)
+	if d > 0 && n.delta > math.MaxInt64-d || d < 0 && n.delta < math.MinInt64-d {
+		return 0, n.wrap(ErrOverflow)
+	}
+	n.delta += d
+	return n.delta, nil
+}
+
+// NibbleXORDelta reads `bits` number of bits from the byte stream and returns
+// them XORed with the value it returned last, as in Gorilla compressed time
+// series where each value is stored XORed with the one before it. The first
+// read since the Nibs was created, `Reset` or `Rewind` returns the bits as
+// they are, seeding the series.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+func (n *Nibs) NibbleXORDelta(bits int) (uint64, error) {
+	v, err := n.Nibble(bits)
+	if err != nil {
+		return 0, err
+	}
+	n.xorPrev ^= v
+	return n.xorPrev, nil
+}
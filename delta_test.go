@@ -0,0 +1,114 @@
+package nibs_test
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+// zigZag returns the zig-zag code of `v`.
+func zigZag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+func TestNibbleDeltaZigZag(t *testing.T) {
+	// a 16-bit seed of -1000 and 6-bit deltas
+	deltas := []int64{0, 5, -3, -20, 31, -32, 1, -7}
+	ba := &BitArray{}
+	ba.AddVar(uint64(0xFC18)<<48, 16)
+	for _, d := range deltas {
+		ba.AddVar(zigZag(d)<<58, 6)
+	}
+	nib := nibs.New(bytes.NewReader(ba.Bytes()))
+
+	expected, err := nib.NibbleDeltaInit(16)
+	if err != nil || expected != -1000 {
+		t.Fatalf("expected seed -1000, got %d and error `%v`", expected, err)
+	}
+	for _, d := range deltas {
+		expected += d
+		if v, err := nib.NibbleDeltaZigZag(6); err != nil || v != expected {
+			t.Errorf("expected %d after delta %d, got %d and error `%v`", expected, d, v, err)
+		}
+	}
+	if _, err := nib.NibbleDeltaZigZag(6); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+
+	// the series restarts from 0 after Reset
+	nib.Reset(bytes.NewReader(PackBits("0000 0011")))
+	if v, err := nib.NibbleDeltaZigZag(4); err != nil || v != 0 {
+		t.Errorf("expected 0, got %d and error `%v`", v, err)
+	}
+	if v, err := nib.NibbleDeltaZigZag(4); err != nil || v != -2 {
+		t.Errorf("expected -2, got %d and error `%v`", v, err)
+	}
+}
+
+func TestNibbleDeltaZigZagErrors(t *testing.T) {
+	ba := &BitArray{}
+	ba.Add64(uint64(math.MaxInt64 - 1))
+	ba.AddVar(zigZag(2)<<56, 8)
+	ba.AddVar(zigZag(1)<<56, 8)
+	nib := nibs.New(bytes.NewReader(ba.Bytes()))
+
+	if _, err := nib.NibbleDeltaInit(64); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := nib.NibbleDeltaZigZag(8); err != nibs.ErrOverflow {
+		t.Errorf("expected `nibs.ErrOverflow`, got `%v`", err)
+	}
+	if v, err := nib.NibbleDeltaZigZag(8); err != nil || v != math.MaxInt64 {
+		t.Errorf("expected %d, got %d and error `%v`", int64(math.MaxInt64), v, err)
+	}
+
+	if _, err := nib.NibbleDeltaZigZag(0); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, err := nib.NibbleDeltaInit(65); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
+
+func TestNibbleXORDelta(t *testing.T) {
+	values := []uint64{
+		math.Float64bits(12.0),
+		math.Float64bits(12.0),
+		math.Float64bits(24.0),
+		math.Float64bits(15.5),
+		math.Float64bits(-15.5),
+	}
+	ba := &BitArray{}
+	var prev uint64
+	for _, v := range values {
+		ba.Add64(v ^ prev)
+		prev = v
+	}
+	nib := nibs.New(bytes.NewReader(ba.Bytes()))
+
+	for _, expected := range values {
+		if v, err := nib.NibbleXORDelta(64); err != nil || v != expected {
+			t.Errorf("expected %v, got %v and error `%v`", math.Float64frombits(expected), math.Float64frombits(v), err)
+		}
+	}
+	if _, err := nib.NibbleXORDelta(64); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+
+	// the first read after Reset seeds a new series
+	nib.Reset(bytes.NewReader(PackBits("1010 0110")))
+	for _, expected := range []uint64{0xA, 0xC} {
+		if v, err := nib.NibbleXORDelta(4); err != nil || v != expected {
+			t.Errorf("expected %#x, got %#x and error `%v`", expected, v, err)
+		}
+	}
+
+	if _, err := nib.NibbleXORDelta(65); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
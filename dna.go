@@ -0,0 +1,99 @@
+package nibs
+
+// ErrAlphabet is the error used when an alphabet does not have one symbol for
+// every value of the symbol width.
+var ErrAlphabet = newSentinel("alphabet size does not match symbol width")
+
+const (
+	// AlphabetACGT maps 2-bit values 0 to 3 to A, C, G and T.
+	AlphabetACGT = "ACGT"
+	// AlphabetTwoBit maps 2-bit values 0 to 3 to T, C, A and G, as used by
+	// the UCSC .2bit format.
+	AlphabetTwoBit = "TCAG"
+	// AlphabetBAM maps 4-bit values to the IUPAC codes used for BAM
+	// sequences, where 0 is '=' and 15 is 'N'.
+	AlphabetBAM = "=ACMGRSVTWYHKDBN"
+)
+
+// NibbleDNA2 reads `count` 2-bit bases into `dst` as ASCII letters using
+// AlphabetACGT. See `NibbleBases` for details.
+func (n *Nibs) NibbleDNA2(count int, dst []byte) (int, error) {
+	return n.NibbleBases(2, count, dst, AlphabetACGT, false)
+}
+
+// NibbleDNA4 reads `count` 4-bit bases into `dst` as ASCII letters using
+// AlphabetBAM. See `NibbleBases` for details.
+func (n *Nibs) NibbleDNA4(count int, dst []byte) (int, error) {
+	return n.NibbleBases(4, count, dst, AlphabetBAM, false)
+}
+
+// NibbleBases reads `count` bases of `bitsEach` bits into `dst`, mapping each
+// value to the ASCII letter at that index of `alphabet`. Bases are read up to
+// 64 bits at a time.
+//
+// When `revComp` is true the reverse complement of the bases read is stored,
+// so the last base read is complemented into dst[0]. IUPAC ambiguity codes are
+// complemented as well.
+//
+// `bitsEach` must be in the range 1 to 8 inclusive, otherwise
+// nibs.ErrNibbleSize is returned. ErrAlphabet is returned if `alphabet` does
+// not have exactly 2^bitsEach letters, and an error wrapping
+// io.ErrShortBuffer if `dst` is shorter than `count`.
+//
+// The number of bases stored is returned. If fewer than `count` bases are
+// stored the error explains why, such as io.EOF at the end of the stream.
+func (n *Nibs) NibbleBases(bitsEach, count int, dst []byte, alphabet string, revComp bool) (int, error) {
+	if bitsEach < 1 || bitsEach > 8 {
+		return 0, n.sizeError("NibbleBases", bitsEach, 8)
+	}
+	if len(alphabet) != 1<<uint(bitsEach) {
+		return 0, n.wrap(ErrAlphabet)
+	}
+	if len(dst) < count {
+		return 0, n.shortError("NibbleBases", count, len(dst))
+	}
+
+	i, err := n.readPacked(count, bitsEach, func(i int, v uint64) { dst[i] = alphabet[v] })
+	if revComp {
+		reverseComplement(dst[:i])
+	}
+	return i, err
+}
+
+// reverseComplement reverses `bases` in place, complementing each IUPAC code.
+func reverseComplement(bases []byte) {
+	for i, j := 0, len(bases)-1; i <= j; i, j = i+1, j-1 {
+		bases[i], bases[j] = complement(bases[j]), complement(bases[i])
+	}
+}
+
+func complement(b byte) byte {
+	switch b {
+	case 'A':
+		return 'T'
+	case 'T', 'U':
+		return 'A'
+	case 'C':
+		return 'G'
+	case 'G':
+		return 'C'
+	case 'M':
+		return 'K'
+	case 'K':
+		return 'M'
+	case 'R':
+		return 'Y'
+	case 'Y':
+		return 'R'
+	case 'B':
+		return 'V'
+	case 'V':
+		return 'B'
+	case 'D':
+		return 'H'
+	case 'H':
+		return 'D'
+	}
+	// W, S, N and = are their own complement
+	return b
+}
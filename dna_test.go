@@ -0,0 +1,112 @@
+package nibs_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+)
+
+// pack2bit packs bases using the UCSC .2bit encoding, T=0, C=1, A=2, G=3.
+func pack2bit(seq string) []byte {
+	buf := make([]byte, (len(seq)+3)/4)
+	for i := 0; i < len(seq); i++ {
+		v := strings.IndexByte(nibs.AlphabetTwoBit, seq[i])
+		buf[i/4] |= byte(v) << uint(6-2*(i%4))
+	}
+	return buf
+}
+
+func TestNibbleDNA2(t *testing.T) {
+	// 00 01 10 11
+	nib := nibs.New(bytes.NewReader([]byte{0x1B}))
+	dst := make([]byte, 4)
+	c, err := nib.NibbleDNA2(4, dst)
+	if err != nil || c != 4 || string(dst) != "ACGT" {
+		t.Errorf("expected ACGT, got %s (%d) and error `%v`", dst[:c], c, err)
+	}
+}
+
+func TestNibbleBasesTwoBit(t *testing.T) {
+	// reference examples from the .2bit format description
+	tests := map[byte]string{0x1B: "TCAG", 0x9C: "ACGT", 0x00: "TTTT", 0xFF: "GGGG"}
+	for b, expected := range tests {
+		nib := nibs.New(bytes.NewReader([]byte{b}))
+		dst := make([]byte, 4)
+		c, err := nib.NibbleBases(2, 4, dst, nibs.AlphabetTwoBit, false)
+		if err != nil || c != 4 || string(dst) != expected {
+			t.Errorf("expected %s for %#x, got %s and error `%v`", expected, b, dst[:c], err)
+		}
+	}
+
+	// a long random sequence which is not a multiple of the bulk read size
+	const size = 100003
+	r := make([]byte, size)
+	if _, err := rand.Read(r); err != nil {
+		panic(err)
+	}
+	for i := range r {
+		r[i] = "ACGT"[r[i]%4]
+	}
+	seq := string(r)
+
+	nib := nibs.New(bytes.NewReader(pack2bit(seq)))
+	dst := make([]byte, size)
+	c, err := nib.NibbleBases(2, size, dst, nibs.AlphabetTwoBit, false)
+	if err != nil || c != size {
+		t.Fatalf("expected %d bases, got %d and error `%v`", size, c, err)
+	}
+	if string(dst) != seq {
+		t.Error("decoded sequence does not match")
+	}
+}
+
+func TestNibbleBasesReverseComplement(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(pack2bit("AACGTTG")))
+	dst := make([]byte, 7)
+	c, err := nib.NibbleBases(2, 7, dst, nibs.AlphabetTwoBit, true)
+	if err != nil || c != 7 || string(dst) != "CAACGTT" {
+		t.Errorf("expected CAACGTT, got %s and error `%v`", dst[:c], err)
+	}
+
+	// BAM alphabet with ambiguity codes
+	nib = nibs.New(bytes.NewReader([]byte{0x13, 0x5F}))
+	dst = make([]byte, 4)
+	c, err = nib.NibbleBases(4, 4, dst, nibs.AlphabetBAM, true)
+	if err != nil || c != 4 || string(dst) != "NYKT" {
+		t.Errorf("expected NYKT, got %s and error `%v`", dst[:c], err)
+	}
+}
+
+func TestNibbleDNA4(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0x12, 0x48, 0xF0}))
+	dst := make([]byte, 6)
+	c, err := nib.NibbleDNA4(6, dst)
+	if err != nil || c != 6 || string(dst) != "ACGTN=" {
+		t.Errorf("expected ACGTN=, got %s and error `%v`", dst[:c], err)
+	}
+}
+
+func TestNibbleBasesErrors(t *testing.T) {
+	// 3 bytes holds 12 bases
+	nib := nibs.New(bytes.NewReader(pack2bit("ACGTACGTACGT")))
+	dst := make([]byte, 40)
+	c, err := nib.NibbleBases(2, 40, dst, nibs.AlphabetTwoBit, false)
+	if err != io.EOF || c != 12 || string(dst[:c]) != "ACGTACGTACGT" {
+		t.Errorf("expected 12 bases and `io.EOF`, got %s (%d) and error `%v`", dst[:c], c, err)
+	}
+
+	if _, err := nib.NibbleBases(2, 4, dst, "ACG", false); err != nibs.ErrAlphabet {
+		t.Errorf("expected `nibs.ErrAlphabet`, got `%v`", err)
+	}
+	if _, err := nib.NibbleBases(9, 4, dst, nibs.AlphabetACGT, false); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, err := nib.NibbleDNA2(41, dst); !errors.Is(err, io.ErrShortBuffer) {
+		t.Errorf("expected error wrapping `io.ErrShortBuffer`, got `%v`", err)
+	}
+}
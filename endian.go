@@ -0,0 +1,52 @@
+package nibs
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// NibbleMiddleEndian32 reads 4 bytes holding a 32-bit value in the
+// middle-endian byte order of the PDP-11, in which the 16-bit halves are
+// stored most significant first but each half is little-endian. The value
+// 0x0A0B0C0D is stored as the bytes 0B 0A 0D 0C.
+//
+// The stream must be byte aligned, otherwise an error wrapping ErrMisaligned
+// is returned and no bits are consumed.
+//
+// See `Nibble` method for details.
+func (n *Nibs) NibbleMiddleEndian32() (uint32, error) {
+	if off := n.offset(); off%8 != 0 {
+		return 0, n.wrap(fmt.Errorf("%w: offset %d is not a multiple of 8 bits", ErrMisaligned, off))
+	}
+	v, err := n.Nibble(32)
+	if err != nil {
+		return 0, err
+	}
+	// swap the bytes within each 16-bit half
+	v = v>>8&0x00FF00FF | v<<8&0xFF00FF00
+	return uint32(v), nil
+}
+
+// NibbleUintSwapped reads `byteCount` bytes and returns them as an unsigned
+// value with the least significant byte first, the reverse of `Nibble`, which
+// treats the first byte as the most significant. This reads little-endian
+// fields of any width up to 8 bytes.
+//
+// `byteCount` must be in the range 1 to 8 inclusive, otherwise
+// nibs.ErrNibbleSize is returned. The stream must be byte aligned, otherwise
+// an error wrapping ErrMisaligned is returned and no bits are consumed.
+//
+// See `Nibble` method for details.
+func (n *Nibs) NibbleUintSwapped(byteCount int) (uint64, error) {
+	if byteCount < 1 || byteCount > 8 {
+		return 0, n.misuseError("NibbleUintSwapped", fmt.Sprintf("%d bytes; must be 1 to 8", byteCount))
+	}
+	if off := n.offset(); off%8 != 0 {
+		return 0, n.wrap(fmt.Errorf("%w: offset %d is not a multiple of 8 bits", ErrMisaligned, off))
+	}
+	v, err := n.Nibble(byteCount * 8)
+	if err != nil {
+		return 0, err
+	}
+	return bits.ReverseBytes64(v) >> uint(64-byteCount*8), nil
+}
@@ -0,0 +1,71 @@
+package nibs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestNibbleMiddleEndian32(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0x0B, 0x0A, 0x0D, 0x0C, 0x34, 0x12, 0x78, 0x56, 0xFF}))
+	for _, expected := range []uint32{0x0A0B0C0D, 0x12345678} {
+		if v, err := nib.NibbleMiddleEndian32(); err != nil || v != expected {
+			t.Errorf("expected %#08x, got %#08x and error `%v`", expected, v, err)
+		}
+	}
+	if _, err := nib.NibbleMiddleEndian32(); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestNibbleMiddleEndian32Misaligned(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xF0, 0x0B, 0x0A, 0x0D, 0x0C}))
+	nib.Nibble(4)
+	if _, err := nib.NibbleMiddleEndian32(); !errors.Is(err, nibs.ErrMisaligned) {
+		t.Errorf("expected `nibs.ErrMisaligned`, got `%v`", err)
+	}
+	nib.Nibble(4)
+	if v, err := nib.NibbleMiddleEndian32(); err != nil || v != 0x0A0B0C0D {
+		t.Errorf("expected 0x0a0b0c0d, got %#08x and error `%v`", v, err)
+	}
+}
+
+func TestNibbleUintSwapped(t *testing.T) {
+	b := []byte{0x34, 0x12, 0x56, 0xEF, 0xCD, 0xAB, 0x08, 0x07, 0x06, 0x05, 0x04, 0x03, 0x02, 0x01, 0x7F}
+	nib := nibs.New(bytes.NewReader(b))
+
+	tests := []struct {
+		byteCount int
+		expected  uint64
+	}{
+		{byteCount: 2, expected: 0x1234},
+		{byteCount: 1, expected: 0x56},
+		{byteCount: 3, expected: 0xABCDEF},
+		{byteCount: 8, expected: 0x0102030405060708},
+	}
+	for _, tt := range tests {
+		if v, err := nib.NibbleUintSwapped(tt.byteCount); err != nil || v != tt.expected {
+			t.Errorf("expected %#x for %d bytes, got %#x and error `%v`", tt.expected, tt.byteCount, v, err)
+		}
+	}
+	if _, err := nib.NibbleUintSwapped(2); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+
+	nib.Nibble(1)
+	if _, err := nib.NibbleUintSwapped(0); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, err := nib.NibbleUintSwapped(9); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+
+	nib = nibs.New(bytes.NewReader(b))
+	nib.Nibble(1)
+	if _, err := nib.NibbleUintSwapped(1); !errors.Is(err, nibs.ErrMisaligned) {
+		t.Errorf("expected `nibs.ErrMisaligned`, got `%v`", err)
+	}
+}
@@ -0,0 +1,85 @@
+package nibs
+
+import "fmt"
+
+// ErrEnumOutOfRange is the error used when an enumerated value read from the
+// stream is not one of the values defined for it.
+var ErrEnumOutOfRange = newSentinel("enum value out of range")
+
+// ErrUnknownVariant is the error used when the tag of a union read from the
+// stream has no variant defined for it.
+var ErrUnknownVariant = newSentinel("unknown union variant")
+
+// NibbleEnumRange reads `bits` number of bits from the byte stream as an
+// enumerated value whose defined values are 0 to `validMax` inclusive, as is
+// usual for dense enums. An error wrapping ErrEnumOutOfRange, and stating the
+// value, is returned for a value greater than `validMax`, such as one reserved
+// for future use. The bits are consumed in that case.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+//
+// See `Nibble` method for details.
+func (n *Nibs) NibbleEnumRange(bits int, validMax uint64) (uint64, error) {
+	v, err := n.Nibble(bits)
+	if err != nil {
+		return 0, err
+	}
+	if v > validMax {
+		return 0, n.wrap(fmt.Errorf("%w: %d, highest defined is %d", ErrEnumOutOfRange, v, validMax))
+	}
+	return v, nil
+}
+
+// NibbleEnumSlice reads `bits` number of bits from the byte stream as an
+// index into `names`, for dense enums numbered from zero, and returns the name
+// along with the value. An error wrapping ErrEnumOutOfRange, and stating the
+// value, is returned for a value with no name. The bits are consumed in that
+// case.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+//
+// See `Nibble` method for details.
+func (n *Nibs) NibbleEnumSlice(bits int, names []string) (string, uint64, error) {
+	v, err := n.Nibble(bits)
+	if err != nil {
+		return "", 0, err
+	}
+	if v >= uint64(len(names)) {
+		return "", 0, n.wrap(fmt.Errorf("%w: %d, %d names defined", ErrEnumOutOfRange, v, len(names)))
+	}
+	return names[v], v, nil
+}
+
+// NibbleUnion reads a discriminated union: a `tagBits` tag followed by a value
+// whose width in bits is given by `variants` for that tag. A width of zero is
+// a variant with no value, and 0 is returned for it without reading further.
+// An error wrapping ErrUnknownVariant, and stating the tag, is returned for a
+// tag not in `variants`. The tag is consumed in that case.
+//
+// `tagBits` and each width read must be in the range 1 to 64 inclusive, or
+// zero for a width, otherwise nibs.ErrNibbleSize is returned.
+//
+// io.EOF is returned if the stream is exhausted before the tag, and
+// io.ErrUnexpectedEOF if it ends part way through the union.
+func (n *Nibs) NibbleUnion(tagBits int, variants map[uint64]int) (tag uint64, value uint64, err error) {
+	defer n.field(&err)()
+
+	tag, err = n.Nibble(tagBits)
+	if err != nil {
+		return 0, 0, err
+	}
+	width, ok := variants[tag]
+	if !ok {
+		return 0, 0, n.wrap(fmt.Errorf("%w: tag %d", ErrUnknownVariant, tag))
+	}
+	if width == 0 {
+		return tag, 0, nil
+	}
+	value, err = n.Nibble(width)
+	if err != nil {
+		return 0, 0, unexpected(err)
+	}
+	return tag, value, nil
+}
@@ -0,0 +1,108 @@
+package nibs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestNibbleEnumRange(t *testing.T) {
+	// 3-bit values 5, 6, 0, 7
+	nib := nibs.New(bytes.NewReader(PackBits("101 110 000 111 0000")))
+
+	if v, err := nib.NibbleEnumRange(3, 5); err != nil || v != 5 {
+		t.Errorf("expected 5, got %d and error `%v`", v, err)
+	}
+	if v, err := nib.NibbleEnumRange(3, 5); !errors.Is(err, nibs.ErrEnumOutOfRange) || v != 0 {
+		t.Errorf("expected `nibs.ErrEnumOutOfRange` for 6, got %d and error `%v`", v, err)
+	}
+	if v, err := nib.NibbleEnumRange(3, 5); err != nil || v != 0 {
+		t.Errorf("expected 0, got %d and error `%v`", v, err)
+	}
+	if _, err := nib.NibbleEnumRange(3, 5); !errors.Is(err, nibs.ErrEnumOutOfRange) {
+		t.Errorf("expected `nibs.ErrEnumOutOfRange` for 7, got `%v`", err)
+	}
+
+	if _, err := nib.NibbleEnumRange(0, 5); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, err := nib.NibbleEnumRange(65, 5); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
+
+func TestNibbleEnumSlice(t *testing.T) {
+	// 2-bit values 2, 0, 3, 1
+	nib := nibs.New(bytes.NewReader(PackBits("10 00 11 01")))
+	four := []string{"none", "low", "medium", "high"}
+
+	for _, expected := range []uint64{2, 0, 3} {
+		name, v, err := nib.NibbleEnumSlice(2, four)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v != expected || name != four[expected] {
+			t.Errorf("expected %d (%s), got %d (%s)", expected, four[expected], v, name)
+		}
+	}
+	if _, _, err := nib.NibbleEnumSlice(2, four); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := nib.NibbleEnumSlice(2, four); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+
+	// 3 is beyond a slice of three names
+	nib = nibs.New(bytes.NewReader(PackBits("11 01 0000")))
+	three := []string{"red", "green", "blue"}
+	if name, v, err := nib.NibbleEnumSlice(2, three); !errors.Is(err, nibs.ErrEnumOutOfRange) || name != "" || v != 0 {
+		t.Errorf("expected `nibs.ErrEnumOutOfRange` for 3, got %q, %d and error `%v`", name, v, err)
+	}
+	if name, v, err := nib.NibbleEnumSlice(2, three); err != nil || name != "green" || v != 1 {
+		t.Errorf("expected 1 (green), got %d (%s) and error `%v`", v, name, err)
+	}
+	if _, _, err := nib.NibbleEnumSlice(65, three); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
+
+func TestNibbleUnion(t *testing.T) {
+	// 2-bit tag: 0 has no value, 1 has 4 bits, 2 has 12 bits, 3 is undefined
+	variants := map[uint64]int{0: 0, 1: 4, 2: 12}
+	nib := nibs.New(bytes.NewReader(PackBits("01 1010 10 101011001101 00 11 10 111111")))
+
+	tests := []struct {
+		tag   uint64
+		value uint64
+	}{
+		{tag: 1, value: 0xA},
+		{tag: 2, value: 0xACD},
+		{tag: 0, value: 0},
+	}
+	for _, tt := range tests {
+		tag, value, err := nib.NibbleUnion(2, variants)
+		if err != nil || tag != tt.tag || value != tt.value {
+			t.Errorf("expected tag %d value %#x, got tag %d value %#x and error `%v`", tt.tag, tt.value, tag, value, err)
+		}
+	}
+
+	if _, _, err := nib.NibbleUnion(2, variants); !errors.Is(err, nibs.ErrUnknownVariant) {
+		t.Errorf("expected `nibs.ErrUnknownVariant` for tag 3, got `%v`", err)
+	}
+	if _, _, err := nib.NibbleUnion(2, variants); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+	nib = nibs.New(bytes.NewReader(nil))
+	if _, _, err := nib.NibbleUnion(2, variants); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+
+	if _, _, err := nib.NibbleUnion(0, variants); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
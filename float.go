@@ -0,0 +1,189 @@
+package nibs
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// ErrInvalidRange is the error used when an invalid range is passed to a read method.
+var ErrInvalidRange = newSentinel("invalid range")
+
+// ErrZeroDenominator is the error used when a fraction read from the stream
+// has a denominator of zero.
+var ErrZeroDenominator = newSentinel("zero denominator")
+
+// NibbleRangeFloat reads `bits` number of bits from the byte stream and maps
+// the unsigned value linearly from [0, 2^bits-1] to [`min`, `max`], as used
+// by telemetry formats that pack an engineering range into a fixed width
+// field. The end points map exactly to `min` and `max`.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned. ErrInvalidRange is returned unless
+// `max` is greater than `min`.
+func (n *Nibs) NibbleRangeFloat(bits int, min, max float64) (float64, error) {
+	if bits < 1 || bits > 64 {
+		return 0, n.sizeError("NibbleRangeFloat", bits, 64)
+	}
+	if !(max > min) {
+		return 0, n.wrap(ErrInvalidRange)
+	}
+
+	v, err := n.Nibble(bits)
+	if err != nil {
+		return 0, err
+	}
+
+	full := ^uint64(0) >> uint(64-bits)
+	if v == full {
+		return max, nil
+	}
+	return min + (max-min)*(float64(v)/float64(full)), nil
+}
+
+// NibbleCalibrated reads `bits` number of bits from the byte stream as a raw
+// sensor reading, two's complement if `signed` is true and unsigned otherwise,
+// and returns raw*`scale` + `offset`, the linear calibration used by sensor
+// and CAN signal definitions.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+func (n *Nibs) NibbleCalibrated(bits int, signed bool, scale, offset float64) (float64, error) {
+	if bits < 1 || bits > 64 {
+		return 0, n.sizeError("NibbleCalibrated", bits, 64)
+	}
+
+	v, err := n.Nibble(bits)
+	if err != nil {
+		return 0, err
+	}
+
+	raw := float64(v)
+	if signed {
+		raw = float64(signExtend(v, bits))
+	}
+	return raw*scale + offset, nil
+}
+
+// NibbleBucket reads `bits` number of bits from the byte stream and maps the
+// unsigned value to a bucket index in [0, `buckets`) by integer scaling, as
+// for quantized histogram indices. The 2^bits values are shared evenly, so
+// value V is bucket floor(V * buckets / 2^bits).
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned. ErrInvalidRange is returned unless
+// `buckets` is greater than 0.
+func (n *Nibs) NibbleBucket(bits, buckets int) (int, error) {
+	if bits < 1 || bits > 64 {
+		return 0, n.sizeError("NibbleBucket", bits, 64)
+	}
+	if buckets <= 0 {
+		return 0, n.wrap(ErrInvalidRange)
+	}
+
+	v, err := n.Nibble(bits)
+	if err != nil {
+		return 0, err
+	}
+
+	return scaleBucket(v, uint(bits), buckets), nil
+}
+
+// scaleBucket returns floor(`v` * `buckets` / 2^`w`) without overflow.
+func scaleBucket(v uint64, w uint, buckets int) int {
+	hi, lo := bits.Mul64(v, uint64(buckets))
+	if w == 64 {
+		return int(hi)
+	}
+	return int(hi<<(64-w) | lo>>w)
+}
+
+// NibbleBFloat16 reads a 16-bit bfloat16 value and returns it as a float32.
+//
+// bfloat16 is the top 16 bits of an IEEE 754 float32, keeping the 8-bit
+// exponent but only 7 bits of mantissa, so it has the range of a float32
+// rather than the range of IEEE half precision. The conversion is exact and
+// preserves infinities and NaNs.
+func (n *Nibs) NibbleBFloat16() (float32, error) {
+	v, err := n.Nibble(16)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float32frombits(uint32(v) << 16), nil
+}
+
+// NibbleSignedFixed reads `bits` number of bits from the byte stream as a two's
+// complement fixed-point value with `fracBits` fraction bits, such as a Q4.4
+// value with `bits` of 8 and `fracBits` of 4, and returns it as a float64.
+// Values of up to 53 significant bits convert exactly.
+//
+// `bits` must be in the range 1 to 64 inclusive, and `fracBits` in the range
+// 0 to `bits`-1 inclusive, otherwise nibs.ErrNibbleSize is returned.
+func (n *Nibs) NibbleSignedFixed(bits, fracBits int) (float64, error) {
+	if bits < 1 || bits > 64 {
+		return 0, n.sizeError("NibbleSignedFixed", bits, 64)
+	}
+	if fracBits < 0 || fracBits >= bits {
+		return 0, n.misuseError("NibbleSignedFixed", fmt.Sprintf("%d fraction bits; must be 0 to %d", fracBits, bits-1))
+	}
+
+	v, err := n.Nibble(bits)
+	if err != nil {
+		return 0, err
+	}
+	return math.Ldexp(float64(signExtend(v, bits)), -fracBits), nil
+}
+
+// NibbleFloatFields reads a floating point value laid out as a sign bit,
+// `expBits` exponent bits and `mantBits` mantissa bits, and returns the raw
+// components, with `bias` subtracted from the exponent. This allows dissecting
+// non-standard float layouts; an IEEE 754 float32 is read with
+// `NibbleFloatFields(8, 23, 127)`. No interpretation of special exponents,
+// such as those of zero, subnormals, infinity and NaN, is made.
+//
+// `expBits` must be at least 1 and `mantBits` at least 0, with
+// 1+`expBits`+`mantBits` at most 64, otherwise nibs.ErrNibbleSize is returned.
+func (n *Nibs) NibbleFloatFields(expBits, mantBits int, bias int) (sign bool, exp int, mantissa uint64, err error) {
+	if expBits < 1 || mantBits < 0 || 1+expBits+mantBits > 64 {
+		return false, 0, 0, n.misuseError("NibbleFloatFields", fmt.Sprintf("%d exponent and %d mantissa bits; must total 63 at most", expBits, mantBits))
+	}
+
+	v, err := n.Nibble(1 + expBits + mantBits)
+	if err != nil {
+		return false, 0, 0, err
+	}
+	sign = v>>uint(expBits+mantBits) == 1
+	exp = int(v>>uint(mantBits)&(1<<uint(expBits)-1)) - bias
+	mantissa = v & (1<<uint(mantBits) - 1)
+	return sign, exp, mantissa, nil
+}
+
+// NibbleRational reads a fraction stored as a `numBits`-bit numerator followed
+// by a `denBits`-bit denominator, and returns both so the caller can form an
+// exact value, such as with big.Rat, without the rounding of a float.
+//
+// `numBits` and `denBits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned. ErrZeroDenominator is returned for a
+// denominator of zero, and the fraction is consumed in that case.
+//
+// io.EOF is returned if the stream is exhausted before the numerator, and
+// io.ErrUnexpectedEOF if it ends part way through the fraction.
+func (n *Nibs) NibbleRational(numBits, denBits int) (num, den uint64, err error) {
+	defer n.field(&err)()
+
+	if denBits < 1 || denBits > 64 {
+		return 0, 0, n.sizeError("NibbleRational", denBits, 64)
+	}
+	num, err = n.Nibble(numBits)
+	if err != nil {
+		return 0, 0, err
+	}
+	den, err = n.Nibble(denBits)
+	if err != nil {
+		return 0, 0, unexpected(err)
+	}
+	if den == 0 {
+		return 0, 0, n.wrap(ErrZeroDenominator)
+	}
+	return num, den, nil
+}
@@ -0,0 +1,270 @@
+package nibs_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/big"
+	"testing"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestNibbleRangeFloat(t *testing.T) {
+	// 10-bit fields: 0, 1023, 511, 512
+	nib := nibs.New(bytes.NewReader(PackBits("0000000000 1111111111 0111111111 1000000000")))
+
+	tests := []struct {
+		expected  float64
+		tolerance float64
+	}{
+		{expected: -1.0},
+		{expected: 1.0},
+		{expected: -1.0 / 1023, tolerance: 1e-12},
+		{expected: 1.0 / 1023, tolerance: 1e-12},
+	}
+
+	for i, tt := range tests {
+		v, err := nib.NibbleRangeFloat(10, -1.0, 1.0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if math.Abs(v-tt.expected) > tt.tolerance {
+			t.Errorf("expected %v for field %d, got %v", tt.expected, i, v)
+		}
+	}
+}
+
+func TestNibbleRangeFloatErrors(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xFF}))
+
+	if _, err := nib.NibbleRangeFloat(0, 0, 1); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, err := nib.NibbleRangeFloat(8, 1, 1); err != nibs.ErrInvalidRange {
+		t.Errorf("expected `nibs.ErrInvalidRange`, got `%v`", err)
+	}
+	if _, err := nib.NibbleRangeFloat(8, math.NaN(), 1); err != nibs.ErrInvalidRange {
+		t.Errorf("expected `nibs.ErrInvalidRange`, got `%v`", err)
+	}
+}
+
+func TestNibbleBFloat16(t *testing.T) {
+	tests := []struct {
+		name string
+		f    float32
+		// checks the decoded value, which may differ from `f` by rounding
+		check func(f float32) bool
+	}{
+		{name: "one", f: 1.0, check: func(f float32) bool { return f == 1.0 }},
+		{name: "negative", f: -2.5, check: func(f float32) bool { return f == -2.5 }},
+		// 1e-40 underflows to zero as IEEE half precision, but not as bfloat16
+		{name: "tiny", f: 1e-40, check: func(f float32) bool { return math.Abs(float64(f)/1e-40-1) < 0.1 }},
+		// 1e38 overflows IEEE half precision (max 65504), but not bfloat16
+		{name: "huge", f: 1e38, check: func(f float32) bool { return math.Abs(float64(f)/1e38-1) < 0.01 }},
+		{name: "+inf", f: float32(math.Inf(1)), check: func(f float32) bool { return math.IsInf(float64(f), 1) }},
+		{name: "-inf", f: float32(math.Inf(-1)), check: func(f float32) bool { return math.IsInf(float64(f), -1) }},
+		{name: "nan", f: float32(math.NaN()), check: func(f float32) bool { return f != f }},
+	}
+
+	for _, tt := range tests {
+		bits := BFloat16Bits(tt.f)
+		nib := nibs.New(bytes.NewReader([]byte{byte(bits >> 8), byte(bits)}))
+		f, err := nib.NibbleBFloat16()
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.name, err)
+			continue
+		}
+		if !tt.check(f) {
+			t.Errorf("unexpected value %v for %s", f, tt.name)
+		}
+	}
+}
+
+func TestNibbleSignedFixed(t *testing.T) {
+	tests := []struct {
+		data     []byte
+		bits     int
+		fracBits int
+		expected float64
+	}{
+		{data: []byte{0x28}, bits: 8, fracBits: 4, expected: 2.5},
+		{data: []byte{0xD8}, bits: 8, fracBits: 4, expected: -2.5},
+		{data: []byte{0x7F}, bits: 8, fracBits: 4, expected: 7.9375},
+		{data: []byte{0x80}, bits: 8, fracBits: 4, expected: -8},
+		{data: []byte{0xFF}, bits: 8, fracBits: 4, expected: -0.0625},
+		{data: []byte{0xFF}, bits: 8, fracBits: 0, expected: -1},
+		{data: []byte{0x80, 0x00}, bits: 16, fracBits: 15, expected: -1},
+		{data: []byte{0x60}, bits: 3, fracBits: 1, expected: 1.5},
+		{data: []byte{0x80, 0, 0, 0, 0, 0, 0, 0}, bits: 64, fracBits: 63, expected: -1},
+	}
+
+	for _, tt := range tests {
+		nib := nibs.New(bytes.NewReader(tt.data))
+		v, err := nib.NibbleSignedFixed(tt.bits, tt.fracBits)
+		if err != nil || v != tt.expected {
+			t.Errorf("expected %v for %x as Q%d.%d, got %v and error `%v`",
+				tt.expected, tt.data, tt.bits-tt.fracBits, tt.fracBits, v, err)
+		}
+	}
+}
+
+func TestNibbleSignedFixedErrors(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0x28}))
+	for _, size := range [][2]int{{0, 0}, {65, 4}, {8, 8}, {8, -1}} {
+		if _, err := nib.NibbleSignedFixed(size[0], size[1]); err != nibs.ErrNibbleSize {
+			t.Errorf("expected `nibs.ErrNibbleSize` for %d/%d bits, got `%v`", size[0], size[1], err)
+		}
+	}
+	if v, err := nib.NibbleSignedFixed(8, 4); err != nil || v != 2.5 {
+		t.Errorf("expected 2.5, got %v and error `%v`", v, err)
+	}
+}
+
+func TestNibbleFloatFields(t *testing.T) {
+	tests := []struct {
+		value    float32
+		sign     bool
+		exp      int
+		mantissa uint64
+	}{
+		{value: 1.0, sign: false, exp: 0, mantissa: 0},
+		{value: -2.5, sign: true, exp: 1, mantissa: 0x200000},
+		{value: 0.15625, sign: false, exp: -3, mantissa: 0x200000},
+		{value: 0, sign: false, exp: -127, mantissa: 0},
+	}
+
+	for _, tt := range tests {
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, math.Float32bits(tt.value))
+		nib := nibs.New(bytes.NewReader(b))
+		sign, exp, mantissa, err := nib.NibbleFloatFields(8, 23, 127)
+		if err != nil || sign != tt.sign || exp != tt.exp || mantissa != tt.mantissa {
+			t.Errorf("expected (%t, %d, %#x) for %v, got (%t, %d, %#x) and error `%v`",
+				tt.sign, tt.exp, tt.mantissa, tt.value, sign, exp, mantissa, err)
+		}
+	}
+}
+
+func TestNibbleFloatFieldsLayouts(t *testing.T) {
+	// a sign and 7-bit exponent without mantissa, then a bfloat16 -1.0 read as 1/8/7
+	nib := nibs.New(bytes.NewReader([]byte{0x80, 0xBF, 0x80}))
+	if sign, exp, mant, err := nib.NibbleFloatFields(7, 0, 63); err != nil || !sign || exp != -63 || mant != 0 {
+		t.Errorf("expected (true, -63, 0), got (%t, %d, %d) and error `%v`", sign, exp, mant, err)
+	}
+	if sign, exp, mant, err := nib.NibbleFloatFields(8, 7, 127); err != nil || !sign || exp != 0 || mant != 0 {
+		t.Errorf("expected (true, 0, 0), got (%t, %d, %d) and error `%v`", sign, exp, mant, err)
+	}
+
+	for _, size := range [][2]int{{0, 23}, {8, -1}, {11, 53}} {
+		if _, _, _, err := nib.NibbleFloatFields(size[0], size[1], 0); err != nibs.ErrNibbleSize {
+			t.Errorf("expected `nibs.ErrNibbleSize` for %d/%d bits, got `%v`", size[0], size[1], err)
+		}
+	}
+}
+
+func TestNibbleCalibrated(t *testing.T) {
+	// 12-bit raw readings 650 and 0xFFF, then a 4-bit one
+	nib := nibs.New(bytes.NewReader([]byte{0x28, 0xAF, 0xFF, 0x70}))
+
+	v, err := nib.NibbleCalibrated(12, false, 0.1, -40)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(v-25) > 1e-9 {
+		t.Errorf("expected 25, got %v", v)
+	}
+	v, err = nib.NibbleCalibrated(12, true, 0.5, 10)
+	if err != nil || v != 9.5 {
+		t.Errorf("expected 9.5, got %v and error `%v`", v, err)
+	}
+	if _, err := nib.NibbleCalibrated(65, false, 1, 0); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	v, err = nib.NibbleCalibrated(4, false, 2, 1)
+	if err != nil || v != 15 {
+		t.Errorf("expected 15, got %v and error `%v`", v, err)
+	}
+	if _, err := nib.NibbleCalibrated(12, false, 1, 0); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestNibbleBucket(t *testing.T) {
+	// 10-bit fields into 8 buckets of 128 values each
+	tests := []struct {
+		value    uint64
+		expected int
+	}{
+		{value: 0, expected: 0},
+		{value: 127, expected: 0},
+		{value: 128, expected: 1},
+		{value: 511, expected: 3},
+		{value: 512, expected: 4},
+		{value: 895, expected: 6},
+		{value: 896, expected: 7},
+		{value: 1023, expected: 7},
+	}
+	ba := &BitArray{}
+	for _, tt := range tests {
+		ba.AddVar(tt.value<<54, 10)
+	}
+	nib := nibs.New(bytes.NewReader(ba.Bytes()))
+	for _, tt := range tests {
+		if b, err := nib.NibbleBucket(10, 8); err != nil || b != tt.expected {
+			t.Errorf("expected bucket %d for %d, got %d and error `%v`", tt.expected, tt.value, b, err)
+		}
+	}
+
+	// 64-bit fields do not overflow
+	nib = nibs.New(bytes.NewReader(bytes.Repeat([]byte{0xFF}, 8)))
+	if b, err := nib.NibbleBucket(64, 1000); err != nil || b != 999 {
+		t.Errorf("expected bucket 999, got %d and error `%v`", b, err)
+	}
+
+	if _, err := nib.NibbleBucket(0, 8); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, err := nib.NibbleBucket(8, 0); err != nibs.ErrInvalidRange {
+		t.Errorf("expected `nibs.ErrInvalidRange`, got `%v`", err)
+	}
+}
+
+func TestNibbleRational(t *testing.T) {
+	// 8-bit numerators over 12-bit denominators: 3/4000, 255/1, then 7/0
+	b := PackBits("00000011 111110100000 11111111 000000000001 00000111 000000000000")
+	nib := nibs.New(bytes.NewReader(b))
+
+	for _, expected := range []*big.Rat{big.NewRat(3, 4000), big.NewRat(255, 1)} {
+		num, den, err := nib.NibbleRational(8, 12)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		r := new(big.Rat).SetFrac(new(big.Int).SetUint64(num), new(big.Int).SetUint64(den))
+		if r.Cmp(expected) != 0 {
+			t.Errorf("expected %v, got %v", expected, r)
+		}
+	}
+	if _, _, err := nib.NibbleRational(8, 12); err != nibs.ErrZeroDenominator {
+		t.Errorf("expected `nibs.ErrZeroDenominator`, got `%v`", err)
+	}
+	if _, _, err := nib.NibbleRational(8, 12); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestNibbleRationalErrors(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0x01, 0x02}))
+	if _, _, err := nib.NibbleRational(0, 8); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, _, err := nib.NibbleRational(8, 65); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, _, err := nib.NibbleRational(8, 16); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+}
@@ -0,0 +1,78 @@
+package nibs
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Unsigned is a constraint that permits any unsigned integer type. It matches
+// constraints.Unsigned from golang.org/x/exp.
+type Unsigned interface {
+	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Signed is a constraint that permits any signed integer type. It matches
+// constraints.Signed from golang.org/x/exp.
+type Signed interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64
+}
+
+// OverflowError is the error returned by `NibbleSignedT` when the value read
+// does not fit in the result type. errors.Is(err, nibs.ErrOverflow) is true
+// for an *OverflowError.
+type OverflowError struct {
+	Value int64 // value read, sign extended
+	Bits  int   // bit size of the result type
+}
+
+func (e *OverflowError) Error() string {
+	return fmt.Sprintf("value %d overflows %d-bit result type", e.Value, e.Bits)
+}
+
+// Is reports whether `target` is ErrOverflow.
+func (e *OverflowError) Is(target error) bool {
+	return target == ErrOverflow
+}
+
+// NibbleT reads `bits` number of bits from the byte stream and returns the
+// value as the unsigned integer type T. It generalizes `Nibble8`, `Nibble16`
+// and `Nibble32` for code that is generic over the result type.
+//
+// `bits` must be in the range 1 to the bit size of T inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+//
+// See `Nibble` method for details.
+func NibbleT[T Unsigned](n *Nibs, bits int) (T, error) {
+	var zero T
+	if bits < 1 || bits > int(unsafe.Sizeof(zero))*8 {
+		return 0, n.sizeError("NibbleT", bits, int(unsafe.Sizeof(zero))*8)
+	}
+	val, err := n.Nibble(bits)
+	return T(val), err
+}
+
+// NibbleSignedT reads `bits` number of bits from the byte stream as a two's
+// complement value, sign extends it from the left-most bit read and returns
+// it as the signed integer type T.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned. `bits` may exceed the bit size of T, in
+// which case an *OverflowError is returned if the value does not fit in T.
+// The bits are consumed in that case.
+//
+// See `Nibble` method for details.
+func NibbleSignedT[T Signed](n *Nibs, bits int) (T, error) {
+	if bits < 1 || bits > 64 {
+		return 0, n.sizeError("NibbleSignedT", bits, 64)
+	}
+	val, err := n.Nibble(bits)
+	if err != nil {
+		return 0, err
+	}
+	v := signExtend(val, bits)
+	if int64(T(v)) != v {
+		var zero T
+		return 0, n.wrap(&OverflowError{Value: v, Bits: int(unsafe.Sizeof(zero)) * 8})
+	}
+	return T(v), nil
+}
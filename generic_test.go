@@ -0,0 +1,141 @@
+package nibs_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"unsafe"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+type register uint16
+
+// checkNibbleT reads a full width value of T from a stream of 0xFF bytes and
+// checks that one more bit than the width of T is rejected.
+func checkNibbleT[T nibs.Unsigned](t *testing.T, name string) {
+	var max T = ^T(0)
+	width := int(unsafe.Sizeof(max)) * 8
+	nib := nibs.New(bytes.NewReader(bytes.Repeat([]byte{0xFF}, 16)))
+
+	v, err := nibs.NibbleT[T](nib, width)
+	if err != nil || v != max {
+		t.Errorf("expected %d for %s, got %d and error `%v`", max, name, v, err)
+	}
+
+	v, err = nibs.NibbleT[T](nib, 3)
+	if err != nil || v != 7 {
+		t.Errorf("expected 7 for %s, got %d and error `%v`", name, v, err)
+	}
+
+	if _, err := nibs.NibbleT[T](nib, width+1); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize` for %s with %d bits, got `%v`", name, width+1, err)
+	}
+	if _, err := nibs.NibbleT[T](nib, 0); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize` for %s with 0 bits, got `%v`", name, err)
+	}
+}
+
+func TestNibbleT(t *testing.T) {
+	checkNibbleT[uint8](t, "uint8")
+	checkNibbleT[uint16](t, "uint16")
+	checkNibbleT[uint32](t, "uint32")
+	checkNibbleT[uint64](t, "uint64")
+	checkNibbleT[uint](t, "uint")
+	checkNibbleT[uintptr](t, "uintptr")
+	checkNibbleT[register](t, "register")
+}
+
+func TestNibbleTMatchesNibble(t *testing.T) {
+	b := []byte{0x12, 0x34, 0x56, 0x78, 0x9A, 0xBC, 0xDE, 0xF0}
+	nibA := nibs.New(bytes.NewReader(b))
+	nibB := nibs.New(bytes.NewReader(b))
+
+	for _, bits := range []int{3, 5, 8, 12, 4} {
+		expected, errA := nibA.Nibble16(bits)
+		v, errB := nibs.NibbleT[uint16](nibB, bits)
+		if errA != nil || errB != nil || v != expected {
+			t.Errorf("expected %d for %d bits, got %d and errors `%v`, `%v`", expected, bits, v, errA, errB)
+		}
+	}
+}
+
+type offset int32
+
+// signedStream returns the values packed as two's complement fields of `bits` bits each.
+func signedStream(bits int, values ...int64) *nibs.Nibs {
+	ba := &BitArray{}
+	for _, v := range values {
+		ba.AddVar(uint64(v)<<uint(64-bits), bits)
+	}
+	return nibs.New(bytes.NewReader(ba.Bytes()))
+}
+
+// checkNibbleSignedT checks the minimum and maximum of T at its own width, and
+// values one wider than T both inside and outside its range.
+func checkNibbleSignedT[T nibs.Signed](t *testing.T, name string) {
+	width := int(unsafe.Sizeof(T(0))) * 8
+	max := int64(1)<<uint(width-1) - 1
+	min := -max - 1
+
+	nib := signedStream(width, min, max, -1, 0)
+	for _, expected := range []int64{min, max, -1, 0} {
+		v, err := nibs.NibbleSignedT[T](nib, width)
+		if err != nil || int64(v) != expected {
+			t.Errorf("expected %d for %s, got %d and error `%v`", expected, name, v, err)
+		}
+	}
+
+	if width == 64 {
+		if _, err := nibs.NibbleSignedT[T](nib, 65); err != nibs.ErrNibbleSize {
+			t.Errorf("expected `nibs.ErrNibbleSize` for %s, got `%v`", name, err)
+		}
+		return
+	}
+
+	nib = signedStream(width+1, min, max, min-1, max+1)
+	for _, expected := range []int64{min, max} {
+		v, err := nibs.NibbleSignedT[T](nib, width+1)
+		if err != nil || int64(v) != expected {
+			t.Errorf("expected %d for %s from %d bits, got %d and error `%v`", expected, name, width+1, v, err)
+		}
+	}
+	for _, expected := range []int64{min - 1, max + 1} {
+		v, err := nibs.NibbleSignedT[T](nib, width+1)
+		var overflow *nibs.OverflowError
+		if !errors.As(err, &overflow) || overflow.Value != expected || overflow.Bits != width || v != 0 {
+			t.Errorf("expected overflow of %d for %s, got %d and error `%v`", expected, name, v, err)
+		}
+		if !errors.Is(err, nibs.ErrOverflow) {
+			t.Errorf("expected error to match `nibs.ErrOverflow` for %s", name)
+		}
+	}
+}
+
+func TestNibbleSignedT(t *testing.T) {
+	checkNibbleSignedT[int8](t, "int8")
+	checkNibbleSignedT[int16](t, "int16")
+	checkNibbleSignedT[int32](t, "int32")
+	checkNibbleSignedT[int64](t, "int64")
+	checkNibbleSignedT[int](t, "int")
+	checkNibbleSignedT[offset](t, "offset")
+}
+
+func TestNibbleSignedTSmall(t *testing.T) {
+	// 3 bits of 101 is -3, 1 bit of 1 is -1
+	nib := nibs.New(bytes.NewReader(PackBits("101 1 0111")))
+	if v, err := nibs.NibbleSignedT[int8](nib, 3); err != nil || v != -3 {
+		t.Errorf("expected -3, got %d and error `%v`", v, err)
+	}
+	if v, err := nibs.NibbleSignedT[int64](nib, 1); err != nil || v != -1 {
+		t.Errorf("expected -1, got %d and error `%v`", v, err)
+	}
+	if v, err := nibs.NibbleSignedT[int16](nib, 4); err != nil || v != 7 {
+		t.Errorf("expected 7, got %d and error `%v`", v, err)
+	}
+	if _, err := nibs.NibbleSignedT[int8](nib, 0); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
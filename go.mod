@@ -1,3 +1,3 @@
 module github.com/wiggin77/nibs
 
-go 1.12
+go 1.18
@@ -0,0 +1,33 @@
+package nibs
+
+// NibbleGraySlice fills `dst` with values of `bitsEach` bits each, read as
+// reflected binary Gray code and converted to binary, as logged by rotary
+// encoders and other position sensors, and returns the number of values read.
+// Several values are read at a time where they fit in 64 bits.
+//
+// `bitsEach` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned. If fewer than len(`dst`) values remain, the
+// values read are returned along with io.EOF if none were read, or
+// io.ErrUnexpectedEOF otherwise.
+func (n *Nibs) NibbleGraySlice(dst []uint64, bitsEach int) (int, error) {
+	if bitsEach < 1 || bitsEach > 64 {
+		return 0, n.sizeError("NibbleGraySlice", bitsEach, 64)
+	}
+
+	c, err := n.readPacked(len(dst), bitsEach, func(i int, v uint64) { dst[i] = grayToBinary(v) })
+	if err != nil && c > 0 {
+		err = unexpected(err)
+	}
+	return c, err
+}
+
+// grayToBinary returns the binary value of the Gray code `g`.
+func grayToBinary(g uint64) uint64 {
+	g ^= g >> 32
+	g ^= g >> 16
+	g ^= g >> 8
+	g ^= g >> 4
+	g ^= g >> 2
+	g ^= g >> 1
+	return g
+}
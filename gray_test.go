@@ -0,0 +1,60 @@
+package nibs_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestNibbleGraySlice(t *testing.T) {
+	// the 4-bit Gray sequence decodes to 0 through 15
+	ba := &BitArray{}
+	for i := uint64(0); i < 16; i++ {
+		ba.AddVar((i^i>>1)<<60, 4)
+	}
+	nib := nibs.New(bytes.NewReader(ba.Bytes()))
+
+	dst := make([]uint64, 16)
+	c, err := nib.NibbleGraySlice(dst, 4)
+	if err != nil || c != 16 {
+		t.Fatalf("expected 16 values, got %d and error `%v`", c, err)
+	}
+	for i, v := range dst {
+		if v != uint64(i) {
+			t.Errorf("value %d: expected %d, got %d", i, i, v)
+		}
+	}
+	if c, err := nib.NibbleGraySlice(dst, 4); err != io.EOF || c != 0 {
+		t.Errorf("expected 0 values and `io.EOF`, got %d and `%v`", c, err)
+	}
+}
+
+func TestNibbleGraySliceWidths(t *testing.T) {
+	// 64-bit Gray code with only the top bit set is the largest value
+	nib := nibs.New(bytes.NewReader([]byte{0x80, 0, 0, 0, 0, 0, 0, 0, 0x80}))
+	dst := make([]uint64, 2)
+	c, err := nib.NibbleGraySlice(dst, 64)
+	if err != io.ErrUnexpectedEOF || c != 1 || dst[0] != ^uint64(0) {
+		t.Errorf("expected [%#x] and `io.ErrUnexpectedEOF`, got %#x, %d and `%v`", ^uint64(0), dst[:c], c, err)
+	}
+
+	// 3-bit codes 000 001 011 010 110 111 101 100 are 0 through 7
+	nib = nibs.New(bytes.NewReader(PackBits("000 001 011 010 110 111 101 100")))
+	dst = make([]uint64, 8)
+	if c, err := nib.NibbleGraySlice(dst, 3); err != nil || c != 8 {
+		t.Fatalf("expected 8 values, got %d and error `%v`", c, err)
+	}
+	for i, v := range dst {
+		if v != uint64(i) {
+			t.Errorf("value %d: expected %d, got %d", i, i, v)
+		}
+	}
+
+	if _, err := nib.NibbleGraySlice(dst, 65); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
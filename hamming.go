@@ -0,0 +1,104 @@
+package nibs
+
+import "fmt"
+
+// UncorrectableError is the error returned when an error correcting code
+// detects more bit errors than it can correct.
+type UncorrectableError struct {
+	Offset   int64  // absolute bit offset of the codeword
+	Codeword uint64 // the codeword as read
+}
+
+func (e *UncorrectableError) Error() string {
+	return fmt.Sprintf("uncorrectable codeword %#x at bit %d", e.Codeword, e.Offset)
+}
+
+// NibbleHamming74 reads a 7-bit Hamming(7,4) codeword, corrects a single
+// flipped bit if needed, and returns the 4 data bits. `corrected` is true if a
+// bit was flipped.
+//
+// The codeword is read in position order p1 p2 d1 p3 d2 d3 d4, where p1, p2
+// and p3 are the parity bits, and the data bits are returned as d1 d2 d3 d4
+// with d1 the most significant.
+//
+// Hamming(7,4) is a perfect code; every 7-bit word is within one bit of a
+// valid codeword, so double bit errors cannot be detected and are
+// miscorrected. Use `NibbleHamming84` when double errors must be flagged.
+func (n *Nibs) NibbleHamming74() (nibble byte, corrected bool, err error) {
+	v, err := n.Nibble(7)
+	if err != nil {
+		return 0, false, err
+	}
+	nibble, corrected = hammingDecode(v)
+	return nibble, corrected, nil
+}
+
+// NibbleHamming84 reads an 8-bit extended Hamming(8,4) codeword, which is a
+// Hamming(7,4) codeword as described in `NibbleHamming74` followed by an
+// overall even parity bit, and returns the 4 data bits. A single flipped bit
+// is corrected and `corrected` is set to true.
+//
+// A double bit error results in an *UncorrectableError.
+func (n *Nibs) NibbleHamming84() (nibble byte, corrected bool, err error) {
+	offset := n.offset()
+	v, err := n.Nibble(8)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var parity uint64
+	for b := v; b != 0; b >>= 1 {
+		parity ^= b & 1
+	}
+	nibble, corrected = hammingDecode(v >> 1)
+	if parity == 0 && corrected {
+		return 0, false, n.wrap(&UncorrectableError{Offset: offset, Codeword: v})
+	}
+	// odd parity with a zero syndrome means the parity bit itself flipped
+	return nibble, parity == 1, nil
+}
+
+// ReadHamming74 decodes Hamming(7,4) codewords from the stream into `p`,
+// packing two data nibbles into each byte with the first in the high nibble.
+// 14 bits are consumed per byte. See `NibbleHamming74` for the codeword layout.
+//
+// The number of bytes filled and the number of codewords that needed a
+// correction are returned. If fewer than len(p) bytes are filled the error
+// explains why.
+func (n *Nibs) ReadHamming74(p []byte) (count int, corrected int, err error) {
+	for i := range p {
+		var b byte
+		for j := 0; j < 2; j++ {
+			nibble, c, err := n.NibbleHamming74()
+			if err != nil {
+				if j > 0 {
+					err = unexpected(err)
+				}
+				return i, corrected, err
+			}
+			if c {
+				corrected++
+			}
+			b = b<<4 | nibble
+		}
+		p[i] = b
+	}
+	return len(p), corrected, nil
+}
+
+// hammingDecode corrects a 7-bit Hamming(7,4) codeword and extracts the data bits.
+func hammingDecode(v uint64) (byte, bool) {
+	// position 1 is the most significant of the 7 bits
+	var syndrome uint
+	for pos := uint(1); pos <= 7; pos++ {
+		if v>>(7-pos)&1 == 1 {
+			syndrome ^= pos
+		}
+	}
+	if syndrome != 0 {
+		v ^= 1 << (7 - syndrome)
+	}
+	// data bits are at positions 3, 5, 6 and 7
+	nibble := byte(v>>4&1)<<3 | byte(v>>2&1)<<2 | byte(v>>1&1)<<1 | byte(v&1)
+	return nibble, syndrome != 0
+}
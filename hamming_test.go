@@ -0,0 +1,116 @@
+package nibs_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestNibbleHamming74(t *testing.T) {
+	// every codeword, uncorrupted and with each single bit flipped
+	for nibble := byte(0); nibble < 16; nibble++ {
+		cw := Hamming74Encode(nibble)
+		for flip := -1; flip < 7; flip++ {
+			word := cw
+			if flip >= 0 {
+				word ^= 1 << uint(flip)
+			}
+			nib := nibs.New(bytes.NewReader([]byte{word << 1}))
+			got, corrected, err := nib.NibbleHamming74()
+			if err != nil {
+				t.Errorf("unexpected error for codeword %07b: %v", word, err)
+				continue
+			}
+			if got != nibble {
+				t.Errorf("expected %x, got %x for codeword %07b", nibble, got, word)
+			}
+			if corrected != (flip >= 0) {
+				t.Errorf("expected corrected=%t for codeword %07b", flip >= 0, word)
+			}
+		}
+	}
+}
+
+func TestNibbleHamming84(t *testing.T) {
+	for nibble := byte(0); nibble < 16; nibble++ {
+		cw := Hamming84Encode(nibble)
+
+		// single bit errors, including in the parity bit, are corrected
+		for flip := -1; flip < 8; flip++ {
+			word := cw
+			if flip >= 0 {
+				word ^= 1 << uint(flip)
+			}
+			nib := nibs.New(bytes.NewReader([]byte{word}))
+			got, corrected, err := nib.NibbleHamming84()
+			if err != nil {
+				t.Errorf("unexpected error for codeword %08b: %v", word, err)
+				continue
+			}
+			if got != nibble || corrected != (flip >= 0) {
+				t.Errorf("expected %x and corrected=%t, got %x and corrected=%t for codeword %08b",
+					nibble, flip >= 0, got, corrected, word)
+			}
+		}
+
+		// double bit errors are detected
+		for i := uint(0); i < 8; i++ {
+			for j := i + 1; j < 8; j++ {
+				word := cw ^ (1 << i) ^ (1 << j)
+				nib := nibs.New(bytes.NewReader([]byte{0xFF, word}))
+				if _, err := nib.Nibble(8); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				_, _, err := nib.NibbleHamming84()
+				var uncorrectable *nibs.UncorrectableError
+				if !errors.As(err, &uncorrectable) {
+					t.Errorf("expected *nibs.UncorrectableError for codeword %08b, got `%v`", word, err)
+					continue
+				}
+				if uncorrectable.Offset != 8 || uncorrectable.Codeword != uint64(word) {
+					t.Errorf("wrong error details: %v", uncorrectable)
+				}
+			}
+		}
+	}
+}
+
+func TestReadHamming74(t *testing.T) {
+	const size = 512
+	bufIn := make([]byte, size)
+	if _, err := rand.Read(bufIn); err != nil {
+		panic(err)
+	}
+
+	// encode every nibble, flipping one bit in every third codeword
+	ba := &BitArray{}
+	var flipped int
+	for i, b := range bufIn {
+		for j, nibble := range []byte{b >> 4, b & 0x0F} {
+			cw := Hamming74Encode(nibble)
+			if (i*2+j)%3 == 0 {
+				cw ^= 1 << uint((i+j)%7)
+				flipped++
+			}
+			ba.AddVar(uint64(cw)<<57, 7)
+		}
+	}
+	nib := nibs.New(bytes.NewReader(ba.Bytes()))
+
+	bufOut := make([]byte, size)
+	count, corrected, err := nib.ReadHamming74(bufOut)
+	if err != nil || count != size {
+		t.Fatalf("expected %d bytes, got %d and error `%v`", size, count, err)
+	}
+	if corrected != flipped {
+		t.Errorf("expected %d corrections, got %d", flipped, corrected)
+	}
+	if !bytes.Equal(bufIn, bufOut) {
+		t.Error("bufIn != bufOut")
+	}
+}
@@ -0,0 +1,127 @@
+package nibs
+
+import "fmt"
+
+// InvalidChipError is the error returned when a line-coded stream contains a
+// chip sequence that is not valid for the coding.
+type InvalidChipError struct {
+	Offset int64  // absolute bit offset of the first chip in the invalid sequence
+	Chips  uint64 // the invalid chips
+}
+
+func (e *InvalidChipError) Error() string {
+	return fmt.Sprintf("invalid chip pair %02b at bit %d", e.Chips, e.Offset)
+}
+
+// NibbleManchester reads `bits` number of Manchester coded bits from the
+// stream and returns the decoded value as a uint64. Each logical bit is coded
+// as two chips, so 2*`bits` bits are consumed.
+//
+// When `ieee` is true the IEEE 802.3 convention is used, where a 01 chip pair
+// is a logical 1 and a 10 chip pair is a logical 0. Otherwise the
+// G.E. Thomas convention is used, where 10 is a logical 1 and 01 is a logical 0.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+//
+// A 00 or 11 chip pair results in an *InvalidChipError. io.ErrUnexpectedEOF is
+// returned if the stream ends part way through the value.
+func (n *Nibs) NibbleManchester(bits int, ieee bool) (value uint64, err error) {
+	defer n.field(&err)()
+
+	if bits < 1 || bits > 64 {
+		return 0, n.sizeError("NibbleManchester", bits, 64)
+	}
+
+	var one uint64 = 2 // chip pair for a logical 1
+	if ieee {
+		one = 1
+	}
+
+	var ret uint64
+	for i := 0; i < bits; i++ {
+		offset := n.offset()
+		chips, err := n.Nibble(2)
+		if err != nil {
+			if i > 0 {
+				return 0, unexpected(err)
+			}
+			return 0, err
+		}
+		if chips == 0 || chips == 3 {
+			return 0, n.wrap(&InvalidChipError{Offset: offset, Chips: chips})
+		}
+		ret = ret << 1
+		if chips == one {
+			ret = ret | 1
+		}
+	}
+	return ret, nil
+}
+
+// ReadManchester decodes Manchester coded bytes from the stream into `p`,
+// consuming 16 bits per decoded byte. See `NibbleManchester` for the meaning
+// of `ieee`.
+//
+// The number of bytes decoded is returned. If fewer than len(p) bytes are
+// decoded the error explains why.
+func (n *Nibs) ReadManchester(p []byte, ieee bool) (int, error) {
+	for i := range p {
+		b, err := n.NibbleManchester(8, ieee)
+		if err != nil {
+			return i, err
+		}
+		p[i] = byte(b)
+	}
+	return len(p), nil
+}
+
+// NibbleNRZI reads `bits` number of NRZI coded chips from the stream and
+// returns the decoded value as a uint64.
+//
+// Each chip is compared with the previous line level; no transition decodes
+// to a 1 and a transition decodes to a 0, as used by USB. When `invert` is
+// true the opposite convention is used. The line level is maintained across
+// calls and starts low; use `SetLineLevel` to seed it at a frame boundary.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+//
+// io.ErrUnexpectedEOF is returned if the stream ends part way through the value.
+func (n *Nibs) NibbleNRZI(bits int, invert bool) (value uint64, err error) {
+	defer n.field(&err)()
+
+	if bits < 1 || bits > 64 {
+		return 0, n.sizeError("NibbleNRZI", bits, 64)
+	}
+
+	var ret uint64
+	for i := 0; i < bits; i++ {
+		chip, err := n.Nibble(1)
+		if err != nil {
+			if i > 0 {
+				return 0, unexpected(err)
+			}
+			return 0, err
+		}
+		level := chip == 1
+		ret = ret << 1
+		if (level == n.level) != invert {
+			ret = ret | 1
+		}
+		n.level = level
+	}
+	return ret, nil
+}
+
+// LineLevel returns the NRZI line level after the last chip read by
+// `NibbleNRZI`, where true is high.
+func (n *Nibs) LineLevel() bool {
+	return n.level
+}
+
+// SetLineLevel sets the NRZI line level that the next chip read by
+// `NibbleNRZI` is compared with, where true is high.
+func (n *Nibs) SetLineLevel(high bool) {
+	n.level = high
+}
@@ -0,0 +1,151 @@
+package nibs_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestNibbleManchester(t *testing.T) {
+	// 0xA5 = 10100101
+	tests := []struct {
+		chips string
+		ieee  bool
+	}{
+		{chips: "01 10 01 10 10 01 10 01", ieee: true},
+		{chips: "10 01 10 01 01 10 01 10", ieee: false},
+	}
+
+	for _, tt := range tests {
+		nib := nibs.New(bytes.NewReader(PackBits(tt.chips)))
+		n, err := nib.NibbleManchester(8, tt.ieee)
+		if err != nil {
+			t.Errorf("unexpected error for ieee=%t: %v", tt.ieee, err)
+			continue
+		}
+		if n != 0xA5 {
+			t.Errorf("expected 0xA5 for ieee=%t, got %#x", tt.ieee, n)
+		}
+	}
+}
+
+func TestNibbleManchesterInvalidChip(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(PackBits("01 10 11 01")))
+	_, err := nib.NibbleManchester(4, true)
+	var chipErr *nibs.InvalidChipError
+	if !errors.As(err, &chipErr) {
+		t.Fatalf("expected *nibs.InvalidChipError, got `%v`", err)
+	}
+	if chipErr.Offset != 4 {
+		t.Errorf("expected offset 4, got %d", chipErr.Offset)
+	}
+
+	// invalid chip pair after several buffer refills
+	const size = 200
+	b := append(ManchesterEncode(make([]byte, size), true), 0xFF)
+	nib = nibs.New(bytes.NewReader(b))
+	if c, err := nib.ReadManchester(make([]byte, size), true); err != nil || c != size {
+		t.Fatalf("expected %d bytes, got %d and error `%v`", size, c, err)
+	}
+	_, err = nib.NibbleManchester(1, true)
+	if !errors.As(err, &chipErr) {
+		t.Fatalf("expected *nibs.InvalidChipError, got `%v`", err)
+	}
+	if chipErr.Offset != size*16 {
+		t.Errorf("expected offset %d, got %d", size*16, chipErr.Offset)
+	}
+}
+
+func TestNibbleManchesterTruncated(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(ManchesterEncode([]byte{0xFF}, true)))
+	if _, err := nib.NibbleManchester(16, true); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+}
+
+func TestReadManchester(t *testing.T) {
+	const size = 1024
+	for _, ieee := range []bool{true, false} {
+		bufIn := make([]byte, size)
+		if _, err := rand.Read(bufIn); err != nil {
+			panic(err)
+		}
+		nib := nibs.New(bytes.NewReader(ManchesterEncode(bufIn, ieee)))
+
+		bufOut := make([]byte, size)
+		c, err := nib.ReadManchester(bufOut, ieee)
+		if err != nil || c != size {
+			t.Errorf("expected %d bytes, got %d and error `%v`", size, c, err)
+		}
+		if !bytes.Equal(bufIn, bufOut) {
+			t.Errorf("bufIn != bufOut for ieee=%t", ieee)
+		}
+
+		// reading any more should be EOF
+		if _, err := nib.ReadManchester(bufOut[:1], ieee); err != io.EOF {
+			t.Errorf("expected error `io.EOF`, got `%v`", err)
+		}
+	}
+}
+
+func TestNibbleNRZI(t *testing.T) {
+	// USB style: 1 is no transition, 0 is a transition, idle low
+	nib := nibs.New(bytes.NewReader(PackBits("1101 0001")))
+	n, err := nib.NibbleNRZI(8, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 0x46 {
+		t.Errorf("expected 0x46, got %#x", n)
+	}
+	if !nib.LineLevel() {
+		t.Error("expected line level high")
+	}
+}
+
+func TestNRZIRoundTrip(t *testing.T) {
+	const size = 1024
+	random := make([]byte, size)
+	if _, err := rand.Read(random); err != nil {
+		panic(err)
+	}
+	payloads := [][]byte{
+		random,
+		make([]byte, size),
+		bytes.Repeat([]byte{0xFF}, size),
+		append(bytes.Repeat([]byte{0x00}, size/2), bytes.Repeat([]byte{0xFF}, size/2)...),
+	}
+
+	for i, bufIn := range payloads {
+		for _, invert := range []bool{false, true} {
+			for _, level := range []bool{false, true} {
+				nib := nibs.New(bytes.NewReader(NRZIEncode(bufIn, invert, level)))
+				nib.SetLineLevel(level)
+
+				// read in varying sizes so the line level is carried across calls
+				baOut := &BitArray{}
+				for remaining := size * 8; remaining > 0; {
+					bits := 1 + remaining%13
+					if bits > remaining {
+						bits = remaining
+					}
+					n, err := nib.NibbleNRZI(bits, invert)
+					if err != nil {
+						t.Fatalf("unexpected error for payload %d: %v", i, err)
+					}
+					baOut.AddVar(n<<uint(64-bits), bits)
+					remaining -= bits
+				}
+				if !bytes.Equal(baOut.Bytes(), bufIn) {
+					t.Errorf("bufIn != bufOut for payload %d, invert=%t, level=%t", i, invert, level)
+				}
+			}
+		}
+	}
+}
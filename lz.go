@@ -0,0 +1,58 @@
+package nibs
+
+// NibbleBackref reads an LZ77 style back-reference made of an `offsetBits`-bit
+// offset followed by a `lengthBits`-bit length, as used by LZSS and similar
+// dictionary coders. It is the bit reading half of an LZ decoder; the caller
+// keeps the window of output and copies `length` bytes starting `offset`
+// bytes back, after applying any bias the format defines, such as a minimum
+// match length.
+//
+// Formats usually mark each item with a flag bit, with literals and
+// back-references interleaved:
+//
+//	for {
+//		flag, err := n.Nibble(1)
+//		if err != nil {
+//			break
+//		}
+//		if flag == 0 {
+//			lit, err := n.Nibble8(8)
+//			if err != nil {
+//				break
+//			}
+//			out = append(out, lit)
+//			continue
+//		}
+//		offset, length, err := n.NibbleBackref(12, 4)
+//		if err != nil {
+//			break
+//		}
+//		for i := uint64(0); i < length; i++ {
+//			out = append(out, out[len(out)-int(offset)])
+//		}
+//	}
+//
+// `offsetBits` and `lengthBits` must each be in the range 1 to 64 inclusive,
+// otherwise nibs.ErrNibbleSize is returned.
+//
+// io.EOF is returned if the stream is exhausted before the offset, and
+// io.ErrUnexpectedEOF if it ends part way through the back-reference.
+func (n *Nibs) NibbleBackref(offsetBits, lengthBits int) (offset, length uint64, err error) {
+	defer n.field(&err)()
+
+	if offsetBits < 1 || offsetBits > 64 {
+		return 0, 0, n.sizeError("NibbleBackref", offsetBits, 64)
+	}
+	if lengthBits < 1 || lengthBits > 64 {
+		return 0, 0, n.sizeError("NibbleBackref", lengthBits, 64)
+	}
+	offset, err = n.Nibble(offsetBits)
+	if err != nil {
+		return 0, 0, err
+	}
+	length, err = n.Nibble(lengthBits)
+	if err != nil {
+		return 0, 0, unexpected(err)
+	}
+	return offset, length, nil
+}
@@ -0,0 +1,86 @@
+package nibs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestNibbleBackref(t *testing.T) {
+	// offset 0x123 in 12 bits, length 5 in 4 bits
+	nib := nibs.New(bytes.NewReader([]byte{0x12, 0x35}))
+	offset, length, err := nib.NibbleBackref(12, 4)
+	if err != nil || offset != 0x123 || length != 5 {
+		t.Errorf("expected (0x123, 5), got (%#x, %d) and error `%v`", offset, length, err)
+	}
+	if _, _, err := nib.NibbleBackref(12, 4); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+
+	nib = nibs.New(bytes.NewReader([]byte{0x12}))
+	if _, _, err := nib.NibbleBackref(4, 8); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+	if _, _, err := nib.NibbleBackref(0, 8); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
+
+// lzDecode decodes flag-prefixed items: a 0 flag and an 8-bit literal, or a
+// 1 flag and a back-reference of a 4-bit offset and 3-bit length.
+func lzDecode(nib *nibs.Nibs) ([]byte, error) {
+	var out []byte
+	for {
+		flag, err := nib.Nibble(1)
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		if flag == 0 {
+			lit, err := nib.Nibble8(8)
+			if err != nil {
+				return out, err
+			}
+			out = append(out, lit)
+			continue
+		}
+		offset, length, err := nib.NibbleBackref(4, 3)
+		if err != nil {
+			return out, err
+		}
+		for i := uint64(0); i < length; i++ {
+			out = append(out, out[len(out)-int(offset)])
+		}
+	}
+}
+
+func TestNibbleBackrefLZ(t *testing.T) {
+	ba := &BitArray{}
+	for _, c := range []byte("abc") {
+		ba.Add(false)
+		ba.Add8(c)
+	}
+	// copy 6 bytes from 3 back, overlapping the output, then a literal
+	ba.Add(true)
+	ba.AddVar(3<<60, 4)
+	ba.AddVar(6<<61, 3)
+	ba.Add(false)
+	ba.Add8('!')
+	// bound the stream so the padding in the final byte is not decoded
+	nib := nibs.New(bytes.NewReader(ba.Bytes()))
+	header, _, err := nib.SplitAt(int64(len(ba.String())))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err := lzDecode(header)
+	if err != nil || string(out) != "abcabcabc!" {
+		t.Errorf("expected `abcabcabc!`, got `%s` and error `%v`", out, err)
+	}
+}
@@ -0,0 +1,81 @@
+package nibs
+
+import "fmt"
+
+// ReadMatrix reads `rows` rows of `cols` cells, each `bitsPerCell` bits wide,
+// into `dst` in row-major order.
+//
+// Each row is padded so its length is a multiple of `rowAlignBits` bits, as
+// with bitmaps that pad rows to a byte or word boundary, and the padding is
+// skipped. Use a `rowAlignBits` of 1 for rows without padding. The padding
+// after the last row is skipped as well.
+//
+// `bitsPerCell` must be in the range 1 to 64 inclusive and `rowAlignBits` must
+// be at least 1, and `rows` and `cols` must not be negative, otherwise
+// nibs.ErrNibbleSize is returned. An error wrapping io.ErrShortBuffer is
+// returned if `dst` holds fewer than `rows`*`cols` cells.
+//
+// If the stream ends before the matrix is complete the error states the row
+// and column reached and wraps io.ErrUnexpectedEOF, or io.EOF if no cells
+// were read. Cells before that point are written to `dst`.
+func (n *Nibs) ReadMatrix(rows, cols, bitsPerCell int, rowAlignBits int, dst []uint64) error {
+	if bitsPerCell < 1 || bitsPerCell > 64 {
+		return n.sizeError("ReadMatrix", bitsPerCell, 64)
+	}
+	if rowAlignBits < 1 {
+		return n.misuseError("ReadMatrix", fmt.Sprintf("row alignment of %d bits; must be at least 1", rowAlignBits))
+	}
+	if rows < 0 || cols < 0 {
+		return n.misuseError("ReadMatrix", fmt.Sprintf("a matrix of %dx%d cells", rows, cols))
+	}
+	if len(dst) < rows*cols {
+		return n.shortError("ReadMatrix", rows*cols, len(dst))
+	}
+
+	rowBits := cols * bitsPerCell
+	padding := (rowAlignBits - rowBits%rowAlignBits) % rowAlignBits
+
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			v, err := n.Nibble(bitsPerCell)
+			if err != nil {
+				if r > 0 || c > 0 {
+					err = unexpected(err)
+				}
+				return n.wrap(fmt.Errorf("matrix row %d, column %d: %w", r, c, err))
+			}
+			dst[r*cols+c] = v
+		}
+		if err := n.Skip(int64(padding)); err != nil {
+			return n.wrap(fmt.Errorf("matrix row %d padding: %w", r, unexpected(err)))
+		}
+	}
+	return nil
+}
+
+// NibbleGrid reads `width`*`height` single bits in row-major order and returns
+// them as a grid of `height` rows of `width` cells, with a 1 bit as true, as
+// used by occupancy grids and 1-bit bitmaps without row padding. Up to 64
+// cells are read at a time.
+//
+// nibs.ErrNibbleSize is returned if `width` or `height` is negative. If the
+// stream ends before the grid is complete the grid is returned with the cells
+// read so far and the rest false, along with io.ErrUnexpectedEOF, or io.EOF if
+// no cells were read.
+func (n *Nibs) NibbleGrid(width, height int) ([][]bool, error) {
+	if width < 0 || height < 0 {
+		return nil, n.misuseError("NibbleGrid", fmt.Sprintf("a grid of %dx%d cells", width, height))
+	}
+
+	cells := make([]bool, width*height)
+	grid := make([][]bool, height)
+	for r := range grid {
+		grid[r] = cells[r*width : (r+1)*width]
+	}
+
+	c, err := n.readPacked(len(cells), 1, func(i int, v uint64) { cells[i] = v == 1 })
+	if err != nil && c > 0 {
+		err = unexpected(err)
+	}
+	return grid, err
+}
@@ -0,0 +1,145 @@
+package nibs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+// 5x7 glyph for 'A', each row padded to a byte
+var glyphA = []byte{0x70, 0x88, 0x88, 0xF8, 0x88, 0x88, 0x88}
+
+var glyphAMatrix = []uint64{
+	0, 1, 1, 1, 0,
+	1, 0, 0, 0, 1,
+	1, 0, 0, 0, 1,
+	1, 1, 1, 1, 1,
+	1, 0, 0, 0, 1,
+	1, 0, 0, 0, 1,
+	1, 0, 0, 0, 1,
+}
+
+func TestReadMatrix(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(glyphA))
+
+	dst := make([]uint64, 5*7)
+	if err := nib.ReadMatrix(7, 5, 1, 8, dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !equalUint64s(dst, glyphAMatrix) {
+		t.Errorf("expected %v, got %v", glyphAMatrix, dst)
+	}
+
+	// padding after the last row is consumed
+	if r, err := nib.BitsRemaining(); err != nil || r != 0 {
+		t.Errorf("expected 0 bits remaining, got %d and error `%v`", r, err)
+	}
+}
+
+func TestReadMatrixWordAligned(t *testing.T) {
+	// 3 cells of 2 bits per row, padded to 16 bits
+	nib := nibs.New(bytes.NewReader(PackBits("00 01 10 0000000000 11 10 01 0000000000")))
+
+	dst := make([]uint64, 6)
+	if err := nib.ReadMatrix(2, 3, 2, 16, dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []uint64{0, 1, 2, 3, 2, 1}
+	if !equalUint64s(dst, expected) {
+		t.Errorf("expected %v, got %v", expected, dst)
+	}
+}
+
+func TestReadMatrixErrors(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(glyphA))
+	err := nib.ReadMatrix(7, 5, 1, 8, make([]uint64, 34))
+	if !errors.Is(err, io.ErrShortBuffer) {
+		t.Errorf("expected error wrapping `io.ErrShortBuffer`, got `%v`", err)
+	}
+
+	if err := nib.ReadMatrix(7, 5, 0, 8, make([]uint64, 35)); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+
+	// truncated after 5 rows
+	nib = nibs.New(bytes.NewReader(glyphA[:5]))
+	err = nib.ReadMatrix(7, 5, 1, 8, make([]uint64, 35))
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected error wrapping `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "row 5, column 0") {
+		t.Errorf("expected error to state row 5, column 0, got `%v`", err)
+	}
+}
+
+// gridString renders a grid with '#' for true and '.' for false, one row per line.
+func gridString(grid [][]bool) string {
+	var sb strings.Builder
+	for _, row := range grid {
+		for _, cell := range row {
+			if cell {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func TestNibbleGrid(t *testing.T) {
+	// 8x8 smiley
+	b := []byte{0x3C, 0x42, 0xA5, 0x81, 0xA5, 0x99, 0x42, 0x3C}
+	expected := "" +
+		"..####..\n" +
+		".#....#.\n" +
+		"#.#..#.#\n" +
+		"#......#\n" +
+		"#.#..#.#\n" +
+		"#..##..#\n" +
+		".#....#.\n" +
+		"..####..\n"
+
+	nib := nibs.New(bytes.NewReader(b))
+	grid, err := nib.NibbleGrid(8, 8)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s := gridString(grid); s != expected {
+		t.Errorf("expected grid:\n%s\ngot:\n%s", expected, s)
+	}
+	if _, err := nib.NibbleGrid(1, 1); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestNibbleGridTruncated(t *testing.T) {
+	// 3x4 grid with only 10 bits present
+	nib := nibs.New(bytes.NewReader(PackBits("101 010 111 1")))
+	header, _, err := nib.SplitAt(10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	grid, err := header.NibbleGrid(3, 4)
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+	expected := "#.#\n.#.\n###\n#..\n"
+	if s := gridString(grid); s != expected {
+		t.Errorf("expected grid:\n%s\ngot:\n%s", expected, s)
+	}
+
+	if _, err := nib.NibbleGrid(-1, 2); err == nil {
+		t.Error("expected error for negative width")
+	}
+	if grid, err := nib.NibbleGrid(0, 3); err != nil || len(grid) != 3 {
+		t.Errorf("expected 3 empty rows, got %v and error `%v`", grid, err)
+	}
+}
@@ -0,0 +1,81 @@
+package nibs
+
+import "fmt"
+
+// ErrRewindLimit is the error used when a read within `Measure` would need
+// more bits buffered than the read buffer holds. See `WithBufferSize`.
+var ErrRewindLimit = newSentinel("read exceeds rewind buffer")
+
+// ErrWithinMeasure is the error returned by methods that may not be called
+// from within `Measure`, such as `Rewind` and `RetryField`.
+var ErrWithinMeasure = newSentinel("called within Measure")
+
+// Measure calls `fn` with `n`, then restores the position within the stream
+// to where it was before the call, and returns the number of bits `fn` read
+// along with any error it returned. This allows choosing between alternative
+// parses by size before committing to one. Calls may be nested.
+//
+// The bits read by `fn` must fit in the read buffer, which is 64 bytes unless
+// set with the option `WithBufferSize`, less the bits of the byte the
+// measurement starts in. Reads that would exceed it return an error wrapping
+// ErrRewindLimit.
+//
+// State affected by reads, such as the NRZI line level, the running
+// Fletcher-16 checksum, the value trace, the fields entered, the previous
+// values of delta coded series and the count returned by `BitsRead`, is
+// restored too. An error from the underlying reader is not, though the bits
+// buffered before it remain readable.
+func (n *Nibs) Measure(fn func(*Nibs) error) (bits int64, err error) {
+	start := n.offset()
+	if n.mark < 0 {
+		n.mark = start
+		defer func() { n.mark = -1 }()
+	}
+
+	var saved state
+	saved.save(n)
+
+	err = fn(n)
+	bits = n.offset() - start
+
+	n.pos = int(start - n.base)
+	saved.restore(n)
+	return bits, err
+}
+
+// ConsumeExact calls `fn` with `n` limited to the next `bits` bits, as for a
+// sub-record of declared size, then skips any of those bits `fn` did not read
+// and returns their number. This lets a parser skip trailing fields added by
+// newer producers. Within `fn`, reads past the limit return io.EOF and
+// `BitsRemaining` counts to the limit.
+//
+// An error returned by `fn` is returned as is, without skipping, along with
+// the number of bits left unread. `bits` must not be negative, otherwise
+// nibs.ErrNibbleSize is returned. io.ErrUnexpectedEOF is returned if the
+// stream ends before the bits left unread.
+func (n *Nibs) ConsumeExact(bits int, fn func(*Nibs) error) (leftover int, err error) {
+	if bits < 0 {
+		return 0, n.misuseError("ConsumeExact", fmt.Sprintf("%d bits; must not be negative", bits))
+	}
+	end := n.offset() + int64(bits)
+	limit := n.limit
+	if limit < 0 || end < limit {
+		n.limit = end
+	}
+
+	err = fn(n)
+	if n.limit == end {
+		// restore the outer limit, unless EOF has since set a nearer one
+		n.limit = limit
+	}
+	leftover = int(end - n.offset())
+	if err != nil {
+		return leftover, err
+	}
+	if leftover > 0 {
+		if err := n.Skip(int64(leftover)); err != nil {
+			return leftover, unexpected(err)
+		}
+	}
+	return leftover, nil
+}
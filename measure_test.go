@@ -0,0 +1,265 @@
+package nibs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestMeasure(t *testing.T) {
+	b := make([]byte, 256)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	nib := nibs.New(bytes.NewReader(b))
+	if _, err := nib.Nibble(5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	bits, err := nib.Measure(func(n *nibs.Nibs) error {
+		if _, err := n.Nibble(16); err != nil {
+			return err
+		}
+		_, err := n.Nibble(7)
+		return err
+	})
+	if err != nil || bits != 23 {
+		t.Errorf("expected 23 bits, got %d and error `%v`", bits, err)
+	}
+	if nib.BitsRead() != 5 {
+		t.Errorf("expected position 5, got %d", nib.BitsRead())
+	}
+	if v, err := nib.Nibble(11); err != nil || v != 0x001 {
+		t.Errorf("expected 0x001, got %#x and error `%v`", v, err)
+	}
+
+	// measure across buffer refills, after many bytes have been read
+	if err := nib.Skip(1000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	before := nib.BitsRead()
+	bits, err = nib.Measure(func(n *nibs.Nibs) error {
+		for i := 0; i < 7; i++ {
+			if _, err := n.Nibble(63); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil || bits != 7*63 {
+		t.Errorf("expected %d bits, got %d and error `%v`", 7*63, bits, err)
+	}
+	if nib.BitsRead() != before {
+		t.Errorf("expected position %d, got %d", before, nib.BitsRead())
+	}
+	// 1016 bits read is byte 127
+	if v, err := nib.Nibble(8); err != nil || v != 127 {
+		t.Errorf("expected 127, got %d and error `%v`", v, err)
+	}
+}
+
+func TestMeasureError(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xAB, 0xCD}))
+	parseErr := errors.New("bad parse")
+	bits, err := nib.Measure(func(n *nibs.Nibs) error {
+		n.Nibble(12)
+		return parseErr
+	})
+	if err != parseErr || bits != 12 {
+		t.Errorf("expected 12 bits and `bad parse`, got %d and `%v`", bits, err)
+	}
+
+	// reading to EOF inside the measurement
+	bits, err = nib.Measure(func(n *nibs.Nibs) error {
+		_, err := n.Nibble(24)
+		return err
+	})
+	if err != io.EOF || bits != 0 {
+		t.Errorf("expected 0 bits and `io.EOF`, got %d and `%v`", bits, err)
+	}
+	if v, err := nib.Nibble(16); err != nil || v != 0xABCD {
+		t.Errorf("expected 0xABCD, got %#x and error `%v`", v, err)
+	}
+}
+
+func TestMeasureRewindLimit(t *testing.T) {
+	b := make([]byte, 1024)
+	readAll := func(n *nibs.Nibs) error {
+		for i := 0; i < 100; i++ {
+			if _, err := n.Nibble(64); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	nib := nibs.New(bytes.NewReader(b))
+	if _, err := nib.Measure(readAll); !errors.Is(err, nibs.ErrRewindLimit) {
+		t.Errorf("expected `nibs.ErrRewindLimit`, got `%v`", err)
+	}
+	// reads after the measurement are unaffected
+	for i := 0; i < 128; i++ {
+		if _, err := nib.Nibble(64); err != nil {
+			t.Fatalf("unexpected error after %d reads: %v", i, err)
+		}
+	}
+
+	nib = nibs.New(bytes.NewReader(b), nibs.WithBufferSize(1024))
+	if bits, err := nib.Measure(readAll); err != nil || bits != 6400 {
+		t.Errorf("expected 6400 bits, got %d and error `%v`", bits, err)
+	}
+}
+
+func TestMeasureRestoresState(t *testing.T) {
+	b := []byte("hello, world")
+	nib := nibs.New(bytes.NewReader(b), nibs.WithFletcher16(), nibs.WithValueTrace())
+	nib.Nibble(16)
+	nib.Enter("outer")
+
+	_, err := nib.Measure(func(n *nibs.Nibs) error {
+		n.Enter("inner")
+		n.ResetCounters()
+		// nested measurement
+		bits, err := n.Measure(func(n *nibs.Nibs) error {
+			_, err := n.Nibble(32)
+			return err
+		})
+		if err != nil || bits != 32 {
+			t.Errorf("expected 32 bits, got %d and error `%v`", bits, err)
+		}
+		_, err = n.Nibble(40)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if nib.Path() != "outer" {
+		t.Errorf("expected path `outer`, got `%s`", nib.Path())
+	}
+	if nib.BitsRead() != 16 {
+		t.Errorf("expected 16 bits read, got %d", nib.BitsRead())
+	}
+	if len(nib.Trace()) != 1 {
+		t.Errorf("expected 1 traced nibble, got %v", nib.Trace())
+	}
+	for i := 2; i < len(b); i++ {
+		nib.Nibble(8)
+	}
+	if sum := nib.Fletcher16(); sum != fletcher16(b) {
+		t.Errorf("expected checksum %#04x, got %#04x", fletcher16(b), sum)
+	}
+
+	// measuring reads ahead leaves delta coded series where they were
+	b = []byte{0x12, 0x34, 0x56}
+	for _, tt := range seriesReads {
+		var expected []int64
+		nib := nibs.New(bytes.NewReader(b))
+		for range b {
+			v, err := tt.read(nib)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.name, err)
+			}
+			expected = append(expected, v)
+		}
+
+		nib = nibs.New(bytes.NewReader(b))
+		for i := range b {
+			nib.Measure(func(n *nibs.Nibs) error {
+				for j := i; j < len(b); j++ {
+					tt.read(n)
+				}
+				return nil
+			})
+			if v, err := tt.read(nib); err != nil || v != expected[i] {
+				t.Errorf("%s: value %d: expected %#x, got %#x and error `%v`", tt.name, i, expected[i], v, err)
+			}
+		}
+	}
+}
+
+// seriesReads read values that depend on the values read before them.
+var seriesReads = []struct {
+	name string
+	read func(*nibs.Nibs) (int64, error)
+}{
+	{"NibbleXORDelta", func(n *nibs.Nibs) (int64, error) {
+		v, err := n.NibbleXORDelta(8)
+		return int64(v), err
+	}},
+	{"NibbleDeltaZigZag", func(n *nibs.Nibs) (int64, error) { return n.NibbleDeltaZigZag(8) }},
+	{"NibbleMonotonic", func(n *nibs.Nibs) (int64, error) {
+		v, err := n.NibbleMonotonic(8)
+		return int64(v), err
+	}},
+	{"NibbleDPCM", func(n *nibs.Nibs) (int64, error) {
+		return n.NibbleDPCM(8, func(history []int64) int64 {
+			if len(history) == 0 {
+				return 0
+			}
+			return history[len(history)-1]
+		})
+	}},
+}
+
+func TestConsumeExact(t *testing.T) {
+	// a 32-bit record of which an older reader knows only the first 12 bits,
+	// then a byte after it
+	nib := nibs.New(bytes.NewReader([]byte{0xAB, 0xC1, 0x23, 0x45, 0x99}))
+
+	var v uint64
+	leftover, err := nib.ConsumeExact(32, func(n *nibs.Nibs) error {
+		var err error
+		v, err = n.Nibble(12)
+		if err != nil {
+			return err
+		}
+		if left, err := n.BitsRemaining(); err != nil || left != 20 {
+			t.Errorf("expected 20 bits remaining in the record, got %d and error `%v`", left, err)
+		}
+		if _, err := n.Nibble(24); err != io.EOF {
+			t.Errorf("expected `io.EOF` reading past the record, got `%v`", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != 0xABC || leftover != 20 {
+		t.Errorf("expected 0xabc and 20 bits left over, got %#x and %d", v, leftover)
+	}
+	if b, err := nib.Nibble(8); err != nil || b != 0x99 {
+		t.Errorf("expected 0x99 after the record, got %#x and error `%v`", b, err)
+	}
+	if _, err := nib.Nibble(1); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestConsumeExactErrors(t *testing.T) {
+	errStop := errors.New("stop")
+	nib := nibs.New(bytes.NewReader([]byte{0x12, 0x34}))
+
+	leftover, err := nib.ConsumeExact(12, func(n *nibs.Nibs) error {
+		if _, err := n.Nibble(4); err != nil {
+			return err
+		}
+		return errStop
+	})
+	if err != errStop || leftover != 8 {
+		t.Errorf("expected `stop` and 8 bits left over, got `%v` and %d", err, leftover)
+	}
+	if v, err := nib.Nibble(4); err != nil || v != 0x2 {
+		t.Errorf("expected 0x2 without skipping, got %#x and error `%v`", v, err)
+	}
+
+	if _, err := nib.ConsumeExact(-1, func(*nibs.Nibs) error { return nil }); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, err := nib.ConsumeExact(16, func(*nibs.Nibs) error { return nil }); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+}
@@ -0,0 +1,76 @@
+package nibs
+
+import (
+	"encoding/binary"
+	"net"
+	"net/netip"
+)
+
+// NibbleIPv4 reads a 32-bit IPv4 address in network byte order.
+// The stream need not be byte aligned.
+func (n *Nibs) NibbleIPv4() (netip.Addr, error) {
+	v, err := n.Nibble(32)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(v))
+	return netip.AddrFrom4(b), nil
+}
+
+// NibbleIPv6 reads a 128-bit IPv6 address in network byte order.
+// The stream need not be byte aligned.
+//
+// io.ErrUnexpectedEOF is returned if the stream ends part way through the address.
+func (n *Nibs) NibbleIPv6() (addr netip.Addr, err error) {
+	defer n.field(&err)()
+
+	var b [16]byte
+	for i := 0; i < 2; i++ {
+		v, err := n.Nibble(64)
+		if err != nil {
+			if i > 0 {
+				err = unexpected(err)
+			}
+			return netip.Addr{}, err
+		}
+		binary.BigEndian.PutUint64(b[i*8:], v)
+	}
+	return netip.AddrFrom16(b), nil
+}
+
+// NibbleAddrPort reads an IPv4 address, or an IPv6 address if `v6` is true,
+// followed by a 16-bit port, all in network byte order.
+//
+// io.ErrUnexpectedEOF is returned if the stream ends part way through.
+func (n *Nibs) NibbleAddrPort(v6 bool) (addrPort netip.AddrPort, err error) {
+	defer n.field(&err)()
+
+	var addr netip.Addr
+	if v6 {
+		addr, err = n.NibbleIPv6()
+	} else {
+		addr, err = n.NibbleIPv4()
+	}
+	if err != nil {
+		return netip.AddrPort{}, err
+	}
+
+	port, err := n.Nibble16(16)
+	if err != nil {
+		return netip.AddrPort{}, unexpected(err)
+	}
+	return netip.AddrPortFrom(addr, port), nil
+}
+
+// NibbleMAC reads a 48-bit MAC address in network byte order, as found in
+// Ethernet frames. The stream need not be byte aligned.
+func (n *Nibs) NibbleMAC() (net.HardwareAddr, error) {
+	v, err := n.Nibble(48)
+	if err != nil {
+		return nil, err
+	}
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	return net.HardwareAddr(b[2:]), nil
+}
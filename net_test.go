@@ -0,0 +1,97 @@
+package nibs_test
+
+import (
+	"bytes"
+	"io"
+	"net/netip"
+	"testing"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+// shifted returns `b` preceded by `lead` one bits, so reads start off a byte boundary.
+func shifted(b []byte, lead int) []byte {
+	ba := &BitArray{}
+	for i := 0; i < lead; i++ {
+		ba.Add(true)
+	}
+	ba.AddSlice(b)
+	return ba.Bytes()
+}
+
+func TestNibbleIPv4(t *testing.T) {
+	expected := netip.MustParseAddr("192.168.1.254")
+	b := expected.As4()
+
+	nib := nibs.New(bytes.NewReader(shifted(b[:], 3)))
+	if _, err := nib.Nibble(3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	addr, err := nib.NibbleIPv4()
+	if err != nil || addr != expected {
+		t.Errorf("expected %v, got %v and error `%v`", expected, addr, err)
+	}
+}
+
+func TestNibbleIPv6(t *testing.T) {
+	expected := netip.MustParseAddr("2001:db8::8a2e:370:7334")
+	b := expected.As16()
+
+	nib := nibs.New(bytes.NewReader(shifted(b[:], 5)))
+	if _, err := nib.Nibble(5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	addr, err := nib.NibbleIPv6()
+	if err != nil || addr != expected {
+		t.Errorf("expected %v, got %v and error `%v`", expected, addr, err)
+	}
+
+	nib = nibs.New(bytes.NewReader(b[:12]))
+	if _, err := nib.NibbleIPv6(); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+}
+
+func TestNibbleAddrPort(t *testing.T) {
+	tests := []string{"10.0.0.1:8080", "[fe80::1]:443"}
+
+	for _, s := range tests {
+		expected := netip.MustParseAddrPort(s)
+		b := expected.Addr().AsSlice()
+		b = append(b, byte(expected.Port()>>8), byte(expected.Port()))
+
+		nib := nibs.New(bytes.NewReader(shifted(b, 1)))
+		if _, err := nib.Nibble(1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ap, err := nib.NibbleAddrPort(expected.Addr().Is6())
+		if err != nil || ap != expected {
+			t.Errorf("expected %v, got %v and error `%v`", expected, ap, err)
+		}
+	}
+
+	nib := nibs.New(bytes.NewReader([]byte{10, 0, 0, 1, 0x1F}))
+	if _, err := nib.NibbleAddrPort(false); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+}
+
+func TestNibbleMAC(t *testing.T) {
+	b := []byte{0x00, 0x1A, 0x2B, 0x3C, 0x4D, 0xFE}
+
+	nib := nibs.New(bytes.NewReader(shifted(b, 7)))
+	if _, err := nib.Nibble(7); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mac, err := nib.NibbleMAC()
+	if err != nil || mac.String() != "00:1a:2b:3c:4d:fe" {
+		t.Errorf("expected 00:1a:2b:3c:4d:fe, got %v and error `%v`", mac, err)
+	}
+
+	nib = nibs.New(bytes.NewReader(b[:5]))
+	if mac, err := nib.NibbleMAC(); err != io.EOF || mac != nil {
+		t.Errorf("expected `io.EOF`, got %v and error `%v`", mac, err)
+	}
+}
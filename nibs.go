@@ -1,37 +1,132 @@
 package nibs
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 )
 
 const (
-	bufSize       = 64
-	readThreshold = bufSize - 16 // point at which another read is needed
+	bufSize       = 64  // default size of the read buffer in bytes
+	minBufSize    = 16  // smallest read buffer; a 64-bit nibble can span 9 bytes
+	maxEmptyReads = 100 // number of reads returning no data before giving up
 )
 
 var (
 	// ErrNibbleSize is the error used when an invalid nibble size is passed to a read method.
-	ErrNibbleSize = errors.New("invalid nibble size")
+	ErrNibbleSize = newSentinel("invalid nibble size")
 
 	// ErrUnknown is the error used when requesting the number of bits left until EOF
 	// and the answer is not yet known because EOF is not reached.
-	ErrUnknown = errors.New("not at EOF")
+	ErrUnknown = newSentinel("not at EOF")
+
+	// ErrOverflow is the error used when a decoded value does not fit in the
+	// type returned by a read method.
+	ErrOverflow = newSentinel("value overflows result type")
 )
 
 // Nibs reads a stream of bytes in nibbles of 1 bit to 64 bits.
 type Nibs struct {
-	reader io.Reader
-	buf    [bufSize]byte
-	used   int   // number of bytes read into buf
-	pos    int   // bit position of next nibble within buf (0-512)
-	err    error // error after last used byte in curr
+	reader   io.Reader
+	buf      []byte
+	used     int      // number of bytes read into buf
+	pos      int      // bit position of next nibble within buf
+	err      error    // error after last used byte in curr
+	base     int64    // number of bits discarded from the front of buf
+	start    int64    // absolute bit offset that BitsRead counts from
+	lead     int      // bits skipped at the start of the reader; see SplitAt
+	limit    int64    // absolute bit offset that reads may not pass, or -1 for no limit
+	knownLen bool     // limit is the length given by a BitLengther
+	mark     int64    // absolute bit offset that must stay buffered, or -1 for none
+	level    bool     // NRZI line level after the last chip read
+	path     []string // names of the fields entered
+
+	opts     []Option // options passed to New
+	zeroFill bool     // zero pad a nibble cut short by EOF; see WithZeroFillEOF
+	panicky  bool     // panic on an invalid nibble size; see WithPanicOnMisuse
+	maxAlloc int      // bytes a read method may allocate, or 0 for no limit
+
+	maxUnaryRun int // longest run of identical bits in a code, or 0 for no limit
+
+	lenientPadding bool // accept padding with bits set; see WithLenientPadding
+
+	scanLimit int64 // bits a scan may skip, or 0 for no limit; see WithScanLimit
+	autoAlign int   // bits to align to after each field, or 0 or 1 for none; see WithAutoAlign
+	depth     int   // fields being read in several parts; see field
+
+	fletcher       bool   // keep a running Fletcher-16; see WithFletcher16
+	fletcherSummed int    // number of bytes at the front of buf already summed
+	fletcherA      uint16 // Fletcher-16 sum of bytes
+	fletcherB      uint16 // Fletcher-16 sum of sums
+
+	bitChecksum func(bit uint8) // called with each bit consumed; see WithBitChecksum
+
+	tracing bool           // record values read; see WithValueTrace
+	trace   []TracedNibble // values read
+
+	stats       bool // count reads; see WithReadStats
+	reads       int  // calls to reader.Read
+	forcedReads int  // reads forced after a short read
+	refills     int  // buffer refills
+
+	shortReadHook func(got, want int) // see WithShortReadHook
+
+	monotonic     uint64  // last value returned by NibbleMonotonic
+	monotonicSeen bool    // monotonic holds a value
+	delta         int64   // last value of a delta coded series; see NibbleDeltaZigZag
+	xorPrev       uint64  // last value returned by NibbleXORDelta
+	dpcm          []int64 // samples returned by NibbleDPCM, most recent last
+
+	retry   bool       // keep the current field buffered; see WithRetryBuffer
+	retryAt retryPoint // start of the current field
+}
+
+// New returns a new Nibs which reads from the specified io.Reader, configured
+// by any options given.
+func New(r io.Reader, opts ...Option) *Nibs {
+	n := &Nibs{}
+	n.init(r, opts, nil)
+	return n
+}
+
+// Reset discards all state, including anything buffered, and makes the Nibs
+// read from `r` as if newly created by `New` with the same options. The
+// buffer is reused.
+func (n *Nibs) Reset(r io.Reader) {
+	n.init(r, n.opts, n.buf)
+}
+
+// init sets up the Nibs to read from `r`, using `buf` as the buffer unless it
+// is nil or an option replaces it.
+func (n *Nibs) init(r io.Reader, opts []Option, buf []byte) {
+	*n = Nibs{reader: r, buf: buf, limit: -1, mark: -1, scanLimit: defaultScanLimit, opts: opts}
+	for _, opt := range opts {
+		opt(n)
+	}
+	if n.buf == nil {
+		n.buf = make([]byte, bufSize)
+	}
+	if bl, ok := r.(BitLengther); ok {
+		n.limit, n.knownLen = bl.BitLen(), true
+	}
+	n.markRetry()
+}
+
+// NewBase64 returns a new Nibs which reads from the specified io.Reader
+// containing standard base64 encoded data, as defined in RFC 4648.
+// Newline characters in the encoded data are ignored.
+func NewBase64(r io.Reader, opts ...Option) *Nibs {
+	return New(base64.NewDecoder(base64.StdEncoding, r), opts...)
 }
 
-// New returns a new Nibs which reads from the specified io.Reader.
-func New(r io.Reader) *Nibs {
-	return &Nibs{reader: r}
+// NewHex returns a new Nibs which reads from the specified io.Reader
+// containing hexadecimal encoded data.
+func NewHex(r io.Reader, opts ...Option) *Nibs {
+	return New(hex.NewDecoder(r), opts...)
 }
 
 // BitsRemaining returns the number of bits that are remaining to be read, if known.
@@ -39,11 +134,96 @@ func New(r io.Reader) *Nibs {
 // then ErrUnknown is returned.
 // If known, reading more than this number causes `Nibble` to return io.EOF.
 // If error is nil and zero is returned then all the bits in the stream have been read.
+// For a Nibs bounded to a number of bits, such as the header returned by `SplitAt`,
+// the answer is also known once the bound has been buffered, and for a reader
+// implementing BitLengther it is known from the start.
 func (n *Nibs) BitsRemaining() (int, error) {
+	remaining := n.remaining()
+	if n.limit >= 0 {
+		left := int(n.limit - n.offset())
+		if left <= remaining || n.knownLen && n.err == nil {
+			return left, nil
+		}
+	}
 	if n.err == nil {
 		return 0, ErrUnknown
 	}
-	return n.remaining(), nil
+	return remaining, nil
+}
+
+// BitLengther is implemented by readers that know the exact number of bits
+// they hold before any are read, such as a framed source that tracks its own
+// bit length. For such a reader passed to `New`, `BitsRemaining` is exact from
+// the start, and reads past the length return io.EOF even if the final byte
+// holds padding bits beyond it.
+type BitLengther interface {
+	// BitLen returns the number of bits the reader holds.
+	BitLen() int64
+}
+
+// TrailingPaddingBits returns the number of bits, 0 to 7, after the end of
+// the stream in its final byte, for validating that a producer padded the
+// stream as expected. The end of the stream must be known exactly, as it is
+// for a reader implementing BitLengther or, once EOF has been reached, for a
+// Nibs returned by `NewText`; otherwise ErrUnknown is returned.
+func (n *Nibs) TrailingPaddingBits() (int, error) {
+	if _, ok := n.reader.(eofBitLengther); !n.knownLen && !(ok && errors.Is(n.err, io.EOF)) {
+		return 0, ErrUnknown
+	}
+	return int((8 - (n.limit+int64(n.lead))%8) % 8), nil
+}
+
+// MaxReadableNow returns the largest number of bits, up to 64, that `Nibble`
+// can read next, reading ahead from the stream if needed to find out. This is
+// 64 unless the end of the stream is near, so a tail can be drained in the
+// largest chunks possible. Zero is returned once all the bits in the stream
+// have been read.
+//
+// An error is returned if the underlying reader returns an error other than
+// io.EOF and no bits are buffered, or if the read ahead fails as described
+// for `Nibble`.
+func (n *Nibs) MaxReadableNow() (int, error) {
+	n.fill(64)
+	left, err := n.BitsRemaining()
+	if err == ErrUnknown {
+		if n.remaining() >= 64 {
+			return 64, nil
+		}
+		if n.used == len(n.buf) {
+			return 0, n.wrap(ErrRewindLimit)
+		}
+		return 0, n.wrap(io.ErrNoProgress)
+	}
+	if left == 0 && n.err != nil && !errors.Is(n.err, io.EOF) {
+		return 0, n.wrap(n.err)
+	}
+	if left > 64 {
+		left = 64
+	}
+	return left, nil
+}
+
+// BitsRead returns the number of bits read from the stream so far, or since
+// the last call to `ResetCounters`.
+func (n *Nibs) BitsRead() int64 {
+	return n.offset() - n.start
+}
+
+// SourceBytesConsumed returns the number of bytes taken from the underlying
+// reader so far, including those buffered but not yet read from the stream
+// and those skipped by seeking. It is the position the reader has advanced
+// to, for a parser that mixes reads from the Nibs with direct reads from the
+// same source. It is not affected by `ResetCounters`, and restarts from zero
+// after `Rewind`.
+func (n *Nibs) SourceBytesConsumed() int64 {
+	return (n.base+int64(n.lead))/8 + int64(n.used)
+}
+
+// ResetCounters restarts the count returned by `BitsRead` from zero, without
+// affecting the position within the stream. Calling it before a sub-structure
+// and `BitsRead` after measures the number of bits the sub-structure used.
+func (n *Nibs) ResetCounters() {
+	n.start = n.offset()
 }
 
 // helper, likely inlined
@@ -51,6 +231,11 @@ func (n *Nibs) remaining() int {
 	return (n.used * 8) - n.pos
 }
 
+// offset returns the absolute bit position of the next nibble within the stream.
+func (n *Nibs) offset() int64 {
+	return n.base + int64(n.pos)
+}
+
 // Nibble reads `bits` number of bits from the byte stream and returns the
 // value as a uint64.
 //
@@ -60,35 +245,106 @@ func (n *Nibs) remaining() int {
 // io.EOF is returned on subsequent call when exactly all the bits in the
 // stream have been read. io.EOF is returned immediately when trying to read
 // more bits than are left in the stream.  Use `BitsRemaining` to see how many
-// bits are left over after io.EOF.
+// bits are left over after io.EOF. No bits are consumed when an error is returned.
 //
-// A value of 0 is always returned for any non-nil error.
+// io.ErrNoProgress is returned if the underlying reader repeatedly returns
+// no data and no error. Any other error from the underlying reader is returned
+// once the bits buffered before it are too few for the read.
+//
+// A value of 0 is always returned for any non-nil error. The exception to this
+// and to no bits being consumed is the *PaddedError returned when the option
+// `WithZeroFillEOF` is used.
 func (n *Nibs) Nibble(bits int) (uint64, error) {
-	if bits < 1 || bits > 64 {
-		return 0, ErrNibbleSize
+	v, err := n.nibble(bits)
+	if err != nil {
+		return v, err
 	}
-	// check if all bits already read or trying to read more bits than available
-	if n.err != nil {
-		remaining := n.remaining()
-		if remaining == 0 {
-			return 0, n.err
+	if n.tracing {
+		n.trace = append(n.trace, TracedNibble{Value: v, Bits: bits})
+	}
+	if err := n.autoAlignField(); err != nil {
+		return v, err
+	}
+	return v, nil
+}
+
+// field marks the start of a field read in several parts, so the option
+// `WithAutoAlign` aligns once after the whole field rather than after each
+// part. The returned function ends the field and, if it is not within another,
+// aligns, setting *err to any error from aligning unless *err is already set.
+func (n *Nibs) field(err *error) func() {
+	n.depth++
+	return func() {
+		n.depth--
+		if *err == nil {
+			*err = n.autoAlignField()
 		}
-		if bits > remaining {
-			return 0, io.EOF
+	}
+}
+
+// autoAlignField skips to the boundary set by the option `WithAutoAlign` after
+// a field, unless within a field read in several parts. Nothing is skipped if
+// the stream ends before the boundary.
+func (n *Nibs) autoAlignField() error {
+	if n.autoAlign <= 1 || n.depth > 0 {
+		return nil
+	}
+	if _, err := n.AlignTo(n.autoAlign); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// withoutZeroFill turns off the option `WithZeroFillEOF`, if used, until the
+// returned function is called, for reads of several values at once and reads
+// draining the stream, which must leave a trailing partial value unread.
+func (n *Nibs) withoutZeroFill() (restore func()) {
+	zeroFill := n.zeroFill
+	n.zeroFill = false
+	return func() { n.zeroFill = zeroFill }
+}
+
+// nibble implements `Nibble` without tracing, for reads that do not return
+// their value to the caller.
+func (n *Nibs) nibble(bits int) (uint64, error) {
+	if bits < 1 || bits > 64 {
+		return 0, n.sizeError("Nibble", bits, 64)
+	}
+	if n.remaining() < bits {
+		n.fill(bits)
+	}
+
+	// check the read does not pass the limit, if any
+	need, limited := bits, false
+	if n.limit >= 0 && int64(bits) > n.limit-n.offset() {
+		if left := int(n.limit - n.offset()); n.zeroFill && left > 0 {
+			need, limited = left, true
+		} else {
+			return 0, n.wrap(io.EOF)
 		}
 	}
 
-	var ret uint64
-	for i := 0; i < bits; i++ {
-		bit, err := n.nextBit()
-		if err != nil {
-			return 0, err
+	// check if all bits already read or trying to read more bits than available
+	if remaining := n.remaining(); need > remaining {
+		if n.err == nil {
+			if n.used == len(n.buf) {
+				return 0, n.wrap(ErrRewindLimit)
+			}
+			return 0, n.wrap(io.ErrNoProgress)
 		}
-		ret = ret << 1
-		bit64 := uint64(bit)
-		ret = ret | uint64(bit64)
+		if remaining == 0 || !errors.Is(n.err, io.EOF) {
+			return 0, n.wrap(n.err)
+		}
+		if n.zeroFill {
+			return n.nibblePadded(bits, remaining)
+		}
+		return 0, n.wrap(io.EOF)
+	}
+	if limited {
+		return n.nibblePadded(bits, need)
 	}
-	return ret, nil
+
+	return n.extract(bits), nil
 }
 
 // Nibble8  reads `bits` number of bits from the byte stream and returns the
@@ -100,7 +356,7 @@ func (n *Nibs) Nibble(bits int) (uint64, error) {
 // See `Nibble` method for details.
 func (n *Nibs) Nibble8(bits int) (uint8, error) {
 	if bits < 1 || bits > 8 {
-		return 0, ErrNibbleSize
+		return 0, n.sizeError("Nibble8", bits, 8)
 	}
 	val, err := n.Nibble(bits)
 	return uint8(val), err
@@ -115,7 +371,7 @@ func (n *Nibs) Nibble8(bits int) (uint8, error) {
 // See `Nibble` method for details.
 func (n *Nibs) Nibble16(bits int) (uint16, error) {
 	if bits < 1 || bits > 16 {
-		return 0, ErrNibbleSize
+		return 0, n.sizeError("Nibble16", bits, 16)
 	}
 	val, err := n.Nibble(bits)
 	return uint16(val), err
@@ -130,58 +386,392 @@ func (n *Nibs) Nibble16(bits int) (uint16, error) {
 // See `Nibble` method for details.
 func (n *Nibs) Nibble32(bits int) (uint32, error) {
 	if bits < 1 || bits > 32 {
-		return 0, ErrNibbleSize
+		return 0, n.sizeError("Nibble32", bits, 32)
 	}
 	val, err := n.Nibble(bits)
 	return uint32(val), err
 }
 
-func (n *Nibs) nextBit() (byte, error) {
-	var bpos = n.pos / 8             // byte index
-	var bposOffset = uint(n.pos % 8) // bit offset within byte
+// NibbleOrDefault reads `bits` number of bits from the byte stream like
+// `Nibble`, except that `def` is returned with a nil error if the stream is
+// cleanly exhausted, meaning all the bits in it have been read. This suits
+// optional fields at the end of a stream.
+//
+// io.ErrUnexpectedEOF is returned if some bits remain but fewer than `bits`.
+//
+// See `Nibble` method for details.
+func (n *Nibs) NibbleOrDefault(bits int, def uint64) (uint64, error) {
+	v, err := n.Nibble(bits)
+	if errors.Is(err, io.EOF) {
+		if left, rerr := n.BitsRemaining(); rerr == nil && left == 0 {
+			return def, nil
+		}
+		return 0, unexpected(err)
+	}
+	return v, err
+}
 
-	// check if we need to read more bytes.
-	if bposOffset == 0 && (bpos == readThreshold || bpos == n.used) {
-		if n.err == nil {
-			// prep for read
-			if bpos > 0 {
-				c := copy(n.buf[:], n.buf[bpos:n.used])
-				n.used = c
-				n.pos = 0
-				bpos = 0
+// NibbleFunc calls `widthFn` to find the number of bits to read, then reads
+// them like `Nibble`. This keeps the logic deciding the width of a field, which
+// may depend on context held by the caller, apart from the read.
+//
+// The width returned must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+//
+// See `Nibble` method for details.
+func (n *Nibs) NibbleFunc(widthFn func() int) (uint64, error) {
+	return n.Nibble(widthFn())
+}
+
+// FieldError is the error returned by read methods while inside one or more
+// fields entered with `Enter`, and outside them for any error other than the
+// sentinel errors of this package and io.EOF and similar, such as a checksum
+// mismatch or an error from the reader. It records the path of the field
+// being read and the absolute bit offset in the stream at which the error
+// occurred.
+type FieldError struct {
+	Path   string // names of the fields entered, separated by dots
+	Offset int64  // absolute bit offset, counted from the start of the stream
+	Err    error
+}
+
+func (e *FieldError) Error() string {
+	at := "bit " + strconv.FormatInt(e.Offset, 10)
+	if e.Path == "" {
+		return at + ": " + e.Err.Error()
+	}
+	return e.Path + " at " + at + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error, so errors.Is(err, io.EOF) and similar
+// checks see through the path.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Enter records that the reads which follow belong to the field `name`, nested
+// within any field already entered. Until the matching `Leave`, errors
+// returned by `Nibble` and the methods built on it are wrapped in a
+// *FieldError holding the path of fields entered, such as "header.flags.version".
+func (n *Nibs) Enter(name string) {
+	n.markRetry()
+	n.path = append(n.path, name)
+}
+
+// Leave ends the field most recently entered with `Enter`. It does nothing if
+// no field has been entered.
+func (n *Nibs) Leave() {
+	if len(n.path) > 0 {
+		n.path = n.path[:len(n.path)-1]
+	}
+}
+
+// Path returns the path of the fields entered with `Enter`, separated by dots,
+// or an empty string if none have been entered.
+func (n *Nibs) Path() string {
+	return strings.Join(n.path, ".")
+}
+
+// sizeError returns the error for an invalid `bits` passed to `method`, which
+// accepts 1 to `max` bits, or panics if the option `WithPanicOnMisuse` is used.
+func (n *Nibs) sizeError(method string, bits, max int) error {
+	return n.misuseError(method, fmt.Sprintf("%d bits; must be 1 to %d", bits, max))
+}
+
+// misuseError returns nibs.ErrNibbleSize for an invalid width or count passed
+// to `method`, described by `arg`, or panics if the option `WithPanicOnMisuse`
+// is used.
+func (n *Nibs) misuseError(method, arg string) error {
+	return n.misuse(method, arg, ErrNibbleSize)
+}
+
+// shortError returns an error wrapping io.ErrShortBuffer for a destination of
+// `have` elements passed to `method`, which needs `need`, or panics if the
+// option `WithPanicOnMisuse` is used.
+func (n *Nibs) shortError(method string, need, have int) error {
+	return n.misuse(method, fmt.Sprintf("a destination of %d; must hold %d", have, need),
+		fmt.Errorf("%w: need %d, destination holds %d", io.ErrShortBuffer, need, have))
+}
+
+// misuse returns `err` for an invalid argument passed to `method`, described
+// by `arg`, or panics if the option `WithPanicOnMisuse` is used.
+func (n *Nibs) misuse(method, arg string, err error) error {
+	if n.panicky {
+		panic(fmt.Sprintf("nibs: %s called with %s", method, arg))
+	}
+	return n.wrap(err)
+}
+
+// wrap adds the path of fields entered, if any, and the bit offset to an
+// error from a read. Outside a field, sentinel errors, those of this package
+// and io.EOF, io.ErrUnexpectedEOF and io.ErrNoProgress, are returned as is so
+// they can be compared with ==, while any other error, such as one from the
+// reader, has the offset added. An error already holding a *FieldError is
+// returned as is.
+func (n *Nibs) wrap(err error) error {
+	if len(n.path) == 0 && isSentinel(err) {
+		return err
+	}
+	var fe *FieldError
+	if errors.As(err, &fe) {
+		return err
+	}
+	return &FieldError{Path: n.Path(), Offset: n.offset(), Err: err}
+}
+
+// sentinelError is the type of the sentinel errors of this package, such as
+// nibs.ErrNibbleSize, which `wrap` returns as is outside a field.
+type sentinelError struct {
+	text string
+}
+
+// newSentinel returns a sentinel error with the given text.
+func newSentinel(text string) error {
+	return &sentinelError{text}
+}
+
+func (e *sentinelError) Error() string {
+	return e.text
+}
+
+// isSentinel reports whether `err` is a sentinel error of this package, or
+// io.EOF, io.ErrUnexpectedEOF or io.ErrNoProgress.
+func isSentinel(err error) bool {
+	if _, ok := err.(*sentinelError); ok {
+		return true
+	}
+	return err == io.EOF || err == io.ErrUnexpectedEOF || err == io.ErrNoProgress
+}
+
+// Skip discards `bits` number of bits from the byte stream.
+//
+// When the reader passed to `New` implements io.Seeker, such as *bytes.Reader
+// or *os.File, large skips move past whole bytes with `Seek` instead of reading
+// them, so skipping a large region of a file costs about the same as skipping a
+// few bits. Other readers, and any Nibs using the option `WithFletcher16`, have
+// the skipped bytes read and discarded.
+//
+// A negative `bits` returns nibs.ErrNibbleSize. io.EOF is returned if fewer
+// than `bits` bits remain in the stream. No bits are consumed in that case when
+// seeking, otherwise bits may have been discarded up to the end of the stream.
+func (n *Nibs) Skip(bits int64) error {
+	if bits < 0 {
+		return n.misuseError("Skip", fmt.Sprintf("%d bits; must not be negative", bits))
+	}
+	if n.limit >= 0 && bits > n.limit-n.offset() {
+		return n.wrap(io.EOF)
+	}
+	if seeker, ok := n.reader.(io.Seeker); ok && n.err == nil && n.mark < 0 && !n.fletcher && n.bitChecksum == nil {
+		skipped, err := n.seek(seeker, bits)
+		if err != nil {
+			return n.wrap(err)
+		}
+		bits -= skipped
+	}
+	for bits > 0 {
+		size := bits
+		if size > 64 {
+			size = 64
+		}
+		if _, err := n.nibble(int(size)); err != nil {
+			return err
+		}
+		bits -= size
+	}
+	return nil
+}
+
+// Rewind returns to the start of the stream so it can be read again, by
+// seeking the reader passed to `New` to offset 0. All state is reset as for a
+// new Nibs with the same options, including the NRZI line level (low), the
+// counters, the fields entered, the running Fletcher-16 checksum and the value
+// trace. For a Nibs returned by `SplitAt`, the start is the split offset.
+//
+// ErrNotSeekable is returned if the reader does not implement io.Seeker, and
+// ErrWithinMeasure if Rewind is called from within `Measure`.
+func (n *Nibs) Rewind() error {
+	seeker, ok := n.reader.(io.Seeker)
+	if !ok {
+		return ErrNotSeekable
+	}
+	if n.mark >= 0 {
+		return ErrWithinMeasure
+	}
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	n.used, n.pos, n.err = 0, 0, nil
+	n.base = -int64(n.lead)
+	var initial state
+	initial.restore(n)
+	if n.lead > 0 {
+		if _, err := n.nibble(n.lead); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	}
+	n.markRetry()
+	return nil
+}
+
+// seek skips the buffered bits and as many whole bytes after them as `bits`
+// allows by seeking the reader, and returns the number of bits skipped. Nothing
+// is skipped if the seek would be shorter than the buffer or the reader cannot
+// actually seek, such as an *os.File reading from a pipe.
+func (n *Nibs) seek(s io.Seeker, bits int64) (int64, error) {
+	buffered := int64(n.remaining())
+	seekBytes := (bits - buffered) / 8
+	if seekBytes < int64(len(n.buf)) {
+		return 0, nil
+	}
+	cur, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, nil
+	}
+	end, err := s.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, nil
+	}
+	if buffered+(end-cur)*8 < bits {
+		if _, err := s.Seek(cur, io.SeekStart); err != nil {
+			n.err = err
+			return 0, err
+		}
+		return 0, io.EOF
+	}
+	if _, err := s.Seek(cur+seekBytes, io.SeekStart); err != nil {
+		n.err = err
+		return 0, err
+	}
+	skipped := buffered + seekBytes*8
+	n.base = n.offset() + skipped
+	n.used, n.pos = 0, 0
+	// the start of the current field is no longer buffered
+	n.retryAt.offset = -1
+	return skipped, nil
+}
+
+// fill reads from the underlying reader until at least `bits` number of bits
+// are buffered, or an error (including io.EOF) occurs, or the reader makes no
+// progress. `bits` must not exceed 64.
+func (n *Nibs) fill(bits int) {
+	empty := 0
+	for n.err == nil && n.remaining() < bits {
+		// prep for read, keeping any marked bits buffered
+		bpos := n.keepFrom()
+		if bpos > 0 {
+			if n.fletcher {
+				n.sumFletcher()
+				n.fletcherSummed -= bpos
+			}
+			n.base += int64(bpos) * 8
+			c := copy(n.buf, n.buf[bpos:n.used])
+			n.used = c
+			n.pos -= bpos * 8
+		}
+		if n.used == len(n.buf) {
+			return // buffer full of marked bits
+		}
+		// read more
+		rbuf := n.buf[n.used:]
+		if n.stats {
+			n.refills++
+			n.reads++
+		}
+		c, err := n.reader.Read(rbuf)
+		n.used += c
+		if err != nil {
+			n.setErr(err)
+		} else if c < len(rbuf) {
+			if n.shortReadHook != nil {
+				n.shortReadHook(c, len(rbuf))
 			}
-			// read more
-			rbuf := n.buf[n.used:]
-			c, err := n.reader.Read(rbuf)
-			n.used += c
+			// we got less than expected and no error; try to force the EOF
+			rbuf = n.buf[n.used:]
+			if n.stats {
+				n.reads++
+				n.forcedReads++
+			}
+			c2, err := n.reader.Read(rbuf)
+			n.used += c2
+			c += c2
 			if err != nil {
-				n.err = err
-			} else if c < len(rbuf) {
-				// we got less than expected and no error; try to force the EOF
-				rbuf = n.buf[n.used:]
-				c, err = n.reader.Read(rbuf)
-				n.used += c
-				if err != nil {
-					n.err = err
-				}
+				n.setErr(err)
+			} else if c2 < len(rbuf) && n.shortReadHook != nil {
+				n.shortReadHook(c2, len(rbuf))
+			}
+		}
+		if c > 0 {
+			empty = 0
+		} else if empty++; empty == maxEmptyReads {
+			return
+		}
+	}
+}
+
+// keepFrom returns the index of the first byte in buf that must stay buffered:
+// the byte holding the next bit, the mark, or the start of the current field
+// when it still fits in buf.
+func (n *Nibs) keepFrom() int {
+	bpos := n.pos / 8
+	if n.mark >= 0 && int((n.mark-n.base)/8) < bpos {
+		bpos = int((n.mark - n.base) / 8)
+	}
+	if n.retry && n.retryAt.offset >= 0 {
+		if rpos := int((n.retryAt.offset - n.base) / 8); rpos < bpos {
+			if rpos == 0 && n.used == len(n.buf) {
+				// the field has outgrown the buffer
+				n.retryAt.offset = -1
+			} else {
+				bpos = rpos
 			}
 		}
 	}
+	return bpos
+}
 
-	// check if we exhausted the available bits.
-	if bpos == n.used {
-		if n.err != nil {
-			return 0, n.err
+// setErr records the error from the underlying reader. At EOF, a reader that
+// knows its exact length in bits limits reads to that length, so the padding
+// in its final byte is not read.
+func (n *Nibs) setErr(err error) {
+	n.err = err
+	if bl, ok := n.reader.(eofBitLengther); ok && errors.Is(err, io.EOF) {
+		if limit := bl.bitLen(); n.limit < 0 || limit < n.limit {
+			n.limit = limit
 		}
-		return 0, fmt.Errorf("BUG: read past avail bytes, but no EOF or error. used=%d, pos=%d", n.used, n.pos)
 	}
+}
 
-	// get the correct byte based on pos
-	b := n.buf[bpos]
-	// shift the bit we want to the rightmost
-	b = b >> (8 - bposOffset - 1)
-	// increment pos to next bit position
-	n.pos++
-	// return 1 or 0
-	return b & 1, nil
+// nibblePadded reads the `avail` bits left in the stream, fewer than `bits`,
+// and returns them left aligned and zero padded to `bits` bits.
+func (n *Nibs) nibblePadded(bits, avail int) (uint64, error) {
+	return n.extract(avail) << uint(bits-avail), n.wrap(&PaddedError{Bits: avail, Width: bits})
+}
+
+// extract returns the next `bits` buffered bits, 1 to 64, accumulating whole
+// bytes and shifting off any excess. The caller must ensure the bits are buffered.
+func (n *Nibs) extract(bits int) uint64 {
+	i := n.pos / 8
+	have := 8 - n.pos%8 // bits in acc, including any past the end of the read
+	acc := uint64(n.buf[i] & (0xFF >> uint(n.pos%8)))
+	for have < bits {
+		i++
+		if have > 56 {
+			// the last byte would overflow acc; take only the bits needed
+			short := uint(bits - have)
+			acc = acc<<short | uint64(n.buf[i]>>(8-short))
+			have = bits
+			break
+		}
+		acc = acc<<8 | uint64(n.buf[i])
+		have += 8
+	}
+	n.pos += bits
+	v := acc >> uint(have-bits)
+	if n.bitChecksum != nil && n.mark < 0 {
+		for i := bits - 1; i >= 0; i-- {
+			n.bitChecksum(uint8(v >> uint(i) & 1))
+		}
+	}
+	return v
 }
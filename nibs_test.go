@@ -4,9 +4,13 @@ import (
 	"bytes"
 	"crypto/rand"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	mrand "math/rand"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	. "github.com/wiggin77/nibs/_test"
 
@@ -294,7 +298,7 @@ func TestNibbleSizes(t *testing.T) {
 			if err != nil {
 				break
 			}
-			baOut.AddVar(n, nibbleSize)
+			baOut.AddVar(n<<uint(64-nibbleSize), nibbleSize)
 		}
 		remaining, err := nib.BitsRemaining()
 		if err != nil {
@@ -307,16 +311,68 @@ func TestNibbleSizes(t *testing.T) {
 				t.Errorf("unexpected error: %v", err)
 				break
 			}
-			baOut.AddVar(n, remaining)
+			baOut.AddVar(n<<uint(64-remaining), remaining)
 		}
 
 		// now check result
-		if !baOut.Equals(baOut) {
+		if !baIn.Equals(baOut) {
 			t.Errorf("baIn != baOut for nibbleSize %d", nibbleSize)
 		}
 	}
 }
 
+// refBits returns `width` bits of `data` starting at bit `off`, read one bit
+// at a time.
+func refBits(data []byte, off, width int) uint64 {
+	var v uint64
+	for i := off; i < off+width; i++ {
+		v = v<<1 | uint64(data[i/8]>>uint(7-i%8)&1)
+	}
+	return v
+}
+
+// test Nibble against a bit at a time reference at random widths and offsets
+func TestNibbleReference(t *testing.T) {
+	rnd := mrand.New(mrand.NewSource(1))
+	data := make([]byte, 256)
+	rnd.Read(data)
+
+	// every wide read at every unaligned position within a byte
+	for width := 57; width <= 64; width++ {
+		for off := 0; off < 16; off++ {
+			nib := nibs.New(bytes.NewReader(data))
+			if err := nib.Skip(int64(off)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			v, err := nib.Nibble(width)
+			if expected := refBits(data, off, width); err != nil || v != expected {
+				t.Errorf("%d bits at bit %d: expected %#x, got %#x and error `%v`", width, off, expected, v, err)
+			}
+		}
+	}
+
+	// runs of random widths, with reads straddling buffer refills
+	readers := map[string]func() io.Reader{
+		"bytes":    func() io.Reader { return bytes.NewReader(data) },
+		"one byte": func() io.Reader { return iotest.OneByteReader(bytes.NewReader(data)) },
+	}
+	for name, reader := range readers {
+		nib := nibs.New(reader(), nibs.WithBufferSize(16))
+		off := 0
+		for {
+			width := 1 + rnd.Intn(64)
+			if off+width > len(data)*8 {
+				break
+			}
+			v, err := nib.Nibble(width)
+			if expected := refBits(data, off, width); err != nil || v != expected {
+				t.Fatalf("%s: %d bits at bit %d: expected %#x, got %#x and error `%v`", name, width, off, expected, v, err)
+			}
+			off += width
+		}
+	}
+}
+
 func TestBitsRemaining(t *testing.T) {
 	bufIn := make([]byte, 10*1000*1024)
 	nib := nibs.New(bytes.NewReader(bufIn))
@@ -326,6 +382,107 @@ func TestBitsRemaining(t *testing.T) {
 	}
 }
 
+// framedReader is a reader that knows its length in bits up front.
+type framedReader struct {
+	*bytes.Reader
+	bits int64
+}
+
+func (f framedReader) BitLen() int64 {
+	return f.bits
+}
+
+func TestBitLengther(t *testing.T) {
+	// 1000 bytes holding 7995 bits, with 5 bits of padding in the last byte
+	r := framedReader{Reader: bytes.NewReader(make([]byte, 1000)), bits: 7995}
+	nib := nibs.New(r)
+
+	if left, err := nib.BitsRemaining(); err != nil || left != 7995 {
+		t.Errorf("expected 7995 bits remaining from the start, got %d and error `%v`", left, err)
+	}
+	if _, err := nib.Nibble(64); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if left, err := nib.BitsRemaining(); err != nil || left != 7931 {
+		t.Errorf("expected 7931 bits remaining, got %d and error `%v`", left, err)
+	}
+	if err := nib.Skip(7930); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := nib.Nibble(2); err != io.EOF {
+		t.Errorf("expected `io.EOF` reading into the padding, got `%v`", err)
+	}
+	if v, err := nib.Nibble(1); err != nil || v != 0 {
+		t.Errorf("expected the last bit, got %d and error `%v`", v, err)
+	}
+
+	// a reader shorter than it claims
+	r = framedReader{Reader: bytes.NewReader(make([]byte, 2)), bits: 100}
+	nib = nibs.New(r)
+	if _, err := nib.Nibble(8); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if left, err := nib.BitsRemaining(); err != nil || left != 8 {
+		t.Errorf("expected 8 bits remaining, got %d and error `%v`", left, err)
+	}
+}
+
+func TestTrailingPaddingBits(t *testing.T) {
+	// 13 bits in 2 bytes leaves 3 bits of padding
+	nib := nibs.New(framedReader{Reader: bytes.NewReader([]byte{0xAB, 0xC8}), bits: 13})
+	if pad, err := nib.TrailingPaddingBits(); err != nil || pad != 3 {
+		t.Errorf("expected 3 padding bits, got %d and error `%v`", pad, err)
+	}
+
+	nib = nibs.NewText(strings.NewReader("1010 1011 1100 1"))
+	if _, err := nib.TrailingPaddingBits(); err != nibs.ErrUnknown {
+		t.Errorf("expected `nibs.ErrUnknown` before EOF, got `%v`", err)
+	}
+	if _, err := nib.Nibble(16); err != io.EOF {
+		t.Fatalf("expected `io.EOF`, got `%v`", err)
+	}
+	if pad, err := nib.TrailingPaddingBits(); err != nil || pad != 3 {
+		t.Errorf("expected 3 padding bits, got %d and error `%v`", pad, err)
+	}
+
+	// a byte reader has no padding to tell
+	nib = nibs.New(bytes.NewReader([]byte{0xAB}))
+	if _, err := nib.Nibble(16); err != io.EOF {
+		t.Fatalf("expected `io.EOF`, got `%v`", err)
+	}
+	if _, err := nib.TrailingPaddingBits(); err != nibs.ErrUnknown {
+		t.Errorf("expected `nibs.ErrUnknown`, got `%v`", err)
+	}
+}
+
+func TestMaxReadableNow(t *testing.T) {
+	b := make([]byte, 1000)
+	for _, r := range []io.Reader{bytes.NewReader(b), iotest.OneByteReader(bytes.NewReader(b))} {
+		nib := nibs.New(r)
+		if max, err := nib.MaxReadableNow(); err != nil || max != 64 {
+			t.Errorf("expected 64 mid-stream, got %d and error `%v`", max, err)
+		}
+		if err := nib.Skip(int64(len(b))*8 - 20); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if max, err := nib.MaxReadableNow(); err != nil || max != 20 {
+			t.Errorf("expected 20 near EOF, got %d and error `%v`", max, err)
+		}
+		if _, err := nib.Nibble(20); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if max, err := nib.MaxReadableNow(); err != nil || max != 0 {
+			t.Errorf("expected 0 at EOF, got %d and error `%v`", max, err)
+		}
+	}
+
+	// a reader error with nothing buffered
+	nib := nibs.New(NewFlakyReader(bytes.NewReader(b), 0))
+	if _, err := nib.MaxReadableNow(); !errors.Is(err, ErrFlaky) {
+		t.Errorf("expected `ErrFlaky`, got `%v`", err)
+	}
+}
+
 func TestNibbleSizeErrors(t *testing.T) {
 	bufIn := make([]byte, 256)
 	nib := nibs.New(bytes.NewReader(bufIn))
@@ -379,3 +536,412 @@ func TestIOError(t *testing.T) {
 		t.Error("full array compare should fail")
 	}
 }
+
+func TestNewBase64(t *testing.T) {
+	// 0xDE 0xAD 0xBE 0xEF
+	nib := nibs.NewBase64(strings.NewReader("3q2+7w=="))
+
+	expected := []uint64{0xD, 0xEAD, 0xBEE, 0xF}
+	sizes := []int{4, 12, 12, 4}
+	for i, size := range sizes {
+		n, err := nib.Nibble(size)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if n != expected[i] {
+			t.Errorf("expected %#x, got %#x", expected[i], n)
+		}
+	}
+
+	if _, err := nib.Nibble(1); err != io.EOF {
+		t.Errorf("expected error `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestNewHex(t *testing.T) {
+	nib := nibs.NewHex(strings.NewReader("deadbeef"))
+
+	n, err := nib.Nibble(32)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if n != 0xDEADBEEF {
+		t.Errorf("expected 0xdeadbeef, got %#x", n)
+	}
+
+	// invalid hex is reported as an error
+	nib = nibs.NewHex(strings.NewReader("zz"))
+	if _, err := nib.Nibble(8); err == nil || err == io.EOF {
+		t.Errorf("expected hex decoding error, got `%v`", err)
+	}
+}
+
+func TestEnterLeave(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xA8}))
+
+	nib.Enter("header")
+	if _, err := nib.Nibble(4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	nib.Enter("flags")
+	nib.Enter("version")
+	if p := nib.Path(); p != "header.flags.version" {
+		t.Errorf("expected path header.flags.version, got %s", p)
+	}
+
+	// not enough bits left
+	_, err := nib.Nibble(8)
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("expected error wrapping `io.EOF`, got `%v`", err)
+	}
+	var fe *nibs.FieldError
+	if !errors.As(err, &fe) || fe.Path != "header.flags.version" {
+		t.Errorf("expected *nibs.FieldError with path header.flags.version, got `%v`", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "header.flags.version") {
+		t.Errorf("expected path in error, got `%v`", err)
+	}
+
+	// multi-part fields report truncation with the path
+	_, err = nib.NibbleFibonacci()
+	if !errors.Is(err, io.ErrUnexpectedEOF) || !errors.As(err, &fe) || fe.Path != "header.flags.version" {
+		t.Errorf("expected *nibs.FieldError wrapping `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+
+	nib.Leave()
+	nib.Leave()
+	if p := nib.Path(); p != "header" {
+		t.Errorf("expected path header, got %s", p)
+	}
+	nib.Leave()
+	nib.Leave()
+
+	// no path, no wrapping
+	if _, err := nib.Nibble(8); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestEnterWrapsTypedErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  []byte
+		read  func(*nibs.Nibs) error
+		check func(error) bool
+	}{
+		{"DOSDateTimeError", []byte{0xC0, 0x00, 0x00, 0x21}, func(n *nibs.Nibs) error {
+			_, err := n.NibbleDOSDateTime()
+			return err
+		}, func(err error) bool {
+			var dtErr *nibs.DOSDateTimeError
+			return errors.As(err, &dtErr) && dtErr.Field == "hour"
+		}},
+		{"InvalidChipError", []byte{0x00}, func(n *nibs.Nibs) error {
+			_, err := n.NibbleManchester(4, true)
+			return err
+		}, func(err error) bool {
+			var chipErr *nibs.InvalidChipError
+			return errors.As(err, &chipErr)
+		}},
+		{"UncorrectableError", []byte{0x06}, func(n *nibs.Nibs) error {
+			_, _, err := n.NibbleHamming84()
+			return err
+		}, func(err error) bool {
+			var hamErr *nibs.UncorrectableError
+			return errors.As(err, &hamErr)
+		}},
+		{"ErrOverflow varint", bytes.Repeat([]byte{0xFF}, 10), func(n *nibs.Nibs) error {
+			_, err := n.NibbleVarintMax(10)
+			return err
+		}, func(err error) bool { return errors.Is(err, nibs.ErrOverflow) }},
+		{"ErrVarintTooLong", []byte{0x80, 0x80}, func(n *nibs.Nibs) error {
+			_, err := n.NibbleVarintMax(2)
+			return err
+		}, func(err error) bool { return errors.Is(err, nibs.ErrVarintTooLong) }},
+		{"ErrOverflow delta", []byte{0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x02}, func(n *nibs.Nibs) error {
+			if _, err := n.NibbleDeltaInit(64); err != nil {
+				return err
+			}
+			_, err := n.NibbleDeltaZigZag(8)
+			return err
+		}, func(err error) bool { return errors.Is(err, nibs.ErrOverflow) }},
+		{"ErrNibbleSize", []byte{0x00}, func(n *nibs.Nibs) error {
+			_, err := n.NibbleBucket(65, 3)
+			return err
+		}, func(err error) bool { return errors.Is(err, nibs.ErrNibbleSize) }},
+	}
+
+	for _, tt := range tests {
+		nib := nibs.New(bytes.NewReader(tt.data))
+		nib.Enter("hdr")
+		nib.Enter("mtime")
+		err := tt.read(nib)
+		var fe *nibs.FieldError
+		if !errors.As(err, &fe) || fe.Path != "hdr.mtime" || !tt.check(err) {
+			t.Errorf("%s: expected *nibs.FieldError with path hdr.mtime, got `%v`", tt.name, err)
+		}
+	}
+}
+
+func TestErrorOffset(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(make([]byte, 6)))
+	if _, err := nib.Nibble(5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a truncated field reports the offset it was cut short at
+	nib.Enter("record")
+	if _, err := nib.Nibble(7); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := nib.Nibble(24); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err := nib.NibblePayloadXOR(2)
+	var fe *nibs.FieldError
+	if !errors.Is(err, io.ErrUnexpectedEOF) || !errors.As(err, &fe) || fe.Offset != 44 {
+		t.Fatalf("expected *nibs.FieldError at bit 44 wrapping `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+	if msg := err.Error(); msg != "record at bit 44: unexpected EOF" {
+		t.Errorf("expected message stating path and offset, got %q", msg)
+	}
+	nib.Leave()
+
+	// outside a field, errors with details carry the offset and sentinels do not
+	if err := nib.VerifyLength(100); !errors.As(err, &fe) || fe.Offset != 44 || fe.Path != "" {
+		t.Errorf("expected *nibs.FieldError at bit 44, got `%v`", err)
+	} else if !strings.HasPrefix(err.Error(), "bit 44: length mismatch") {
+		t.Errorf("expected message starting with offset, got %q", err.Error())
+	}
+	if _, err := nib.Nibble(8); err != io.EOF {
+		t.Errorf("expected bare `io.EOF`, got `%v`", err)
+	}
+
+	// a reader error that cuts a read short carries the offset too
+	nib = nibs.New(NewFlakyReader(bytes.NewReader(make([]byte, 2)), 2))
+	if _, err := nib.Nibble(12); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = nib.Nibble(8)
+	if !errors.Is(err, ErrFlaky) || !errors.As(err, &fe) || fe.Offset != 12 || fe.Path != "" {
+		t.Errorf("expected *nibs.FieldError at bit 12 wrapping `ErrFlaky`, got `%v`", err)
+	}
+	nib = nibs.New(bytes.NewReader([]byte{0xC0, 0x00, 0x00, 0x21}))
+	if _, err := nib.NibbleDOSDateTime(); !errors.As(err, &fe) || fe.Offset != 32 {
+		t.Errorf("expected *nibs.FieldError at bit 32, got `%v`", err)
+	}
+}
+
+func TestNibbleOrDefault(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xAB, 0xCD}))
+
+	// fully present
+	if v, err := nib.NibbleOrDefault(12, 99); err != nil || v != 0xABC {
+		t.Errorf("expected 0xABC, got %#x and error `%v`", v, err)
+	}
+
+	// partially present
+	if _, err := nib.NibbleOrDefault(8, 99); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+	if v, err := nib.NibbleOrDefault(4, 99); err != nil || v != 0xD {
+		t.Errorf("expected 0xD, got %#x and error `%v`", v, err)
+	}
+
+	// cleanly exhausted
+	for i := 0; i < 2; i++ {
+		if v, err := nib.NibbleOrDefault(8, 99); err != nil || v != 99 {
+			t.Errorf("expected default 99, got %d and error `%v`", v, err)
+		}
+	}
+
+	// other errors are returned as is
+	if _, err := nib.NibbleOrDefault(0, 99); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
+
+func TestResetCounters(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xAB, 0xCD, 0xEF}))
+	if _, err := nib.Nibble(10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	nib.ResetCounters()
+	if nib.BitsRead() != 0 {
+		t.Errorf("expected 0 bits read after reset, got %d", nib.BitsRead())
+	}
+
+	// position within the stream is unaffected
+	v, err := nib.Nibble(6)
+	if err != nil || v != 0x0D {
+		t.Errorf("expected 0x0D, got %#x and error `%v`", v, err)
+	}
+	if nib.BitsRead() != 6 {
+		t.Errorf("expected 6 bits read, got %d", nib.BitsRead())
+	}
+	if skipped, err := nib.AlignTo(16); err != nil || skipped != 0 {
+		t.Errorf("expected stream already aligned, got %d bits skipped and error `%v`", skipped, err)
+	}
+	if left, err := nib.BitsRemaining(); err != nil || left != 8 {
+		t.Errorf("expected 8 bits remaining, got %d and error `%v`", left, err)
+	}
+}
+
+func TestSourceBytesConsumed(t *testing.T) {
+	data := make([]byte, 200)
+	r := bytes.NewReader(data)
+	nib := nibs.New(r, nibs.WithBufferSize(8))
+	consumed := func() int64 { return r.Size() - int64(r.Len()) }
+
+	if nib.SourceBytesConsumed() != 0 {
+		t.Errorf("expected 0 bytes consumed, got %d", nib.SourceBytesConsumed())
+	}
+	for _, bits := range []int{3, 64, 17, 64, 64, 1} {
+		if _, err := nib.Nibble(bits); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got, expected := nib.SourceBytesConsumed(), consumed(); got != expected {
+			t.Errorf("after %d bits read: expected %d bytes consumed, got %d", nib.BitsRead(), expected, got)
+		}
+	}
+
+	// a long skip seeks the reader
+	if err := nib.Skip(800); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, expected := nib.SourceBytesConsumed(), consumed(); got != expected {
+		t.Errorf("after skip: expected %d bytes consumed, got %d", expected, got)
+	}
+
+	if err := nib.Rewind(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nib.SourceBytesConsumed() != 0 {
+		t.Errorf("expected 0 bytes consumed after rewind, got %d", nib.SourceBytesConsumed())
+	}
+}
+
+func TestReset(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xAB, 0xCD}), nibs.WithZeroFillEOF())
+	if _, err := nib.Nibble(12); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// reads restart on the new reader, with nothing left from the old one
+	nib.Reset(bytes.NewReader([]byte{0x12, 0x34}))
+	if nib.BitsRead() != 0 {
+		t.Errorf("expected 0 bits read after reset, got %d", nib.BitsRead())
+	}
+	if v, err := nib.Nibble(12); err != nil || v != 0x123 {
+		t.Errorf("expected 0x123, got %#x and error `%v`", v, err)
+	}
+
+	// options still apply
+	if v, err := nib.Nibble(8); !errors.Is(err, nibs.ErrPadded) || v != 0x40 {
+		t.Errorf("expected 0x40 and `nibs.ErrPadded`, got %#x and error `%v`", v, err)
+	}
+}
+
+// test readers that return the last of their data together with io.EOF
+func TestDataWithEOF(t *testing.T) {
+	for _, size := range []int{1, 7, 8, 63, 64, 65, 127, 128, 129, 500} {
+		bufIn := make([]byte, size)
+		if _, err := rand.Read(bufIn); err != nil {
+			panic(err)
+		}
+		readers := []struct {
+			name string
+			make func() io.Reader
+		}{
+			{"DataErrReader", func() io.Reader {
+				return iotest.DataErrReader(bytes.NewReader(bufIn))
+			}},
+			{"OneByteReader", func() io.Reader {
+				return iotest.DataErrReader(iotest.OneByteReader(bytes.NewReader(bufIn)))
+			}},
+		}
+
+		for _, rt := range readers {
+			name := rt.name
+			for _, width := range []int{1, 3, 8, 13, 64} {
+				nib := nibs.New(rt.make())
+
+				// read every bit, with a final read of whatever is left over
+				baIn, baOut := &BitArray{}, &BitArray{}
+				baIn.AddSlice(bufIn)
+				for remaining := size * 8; remaining > 0; {
+					bits := width
+					if bits > remaining {
+						bits = remaining
+					}
+					v, err := nib.Nibble(bits)
+					if err != nil {
+						t.Fatalf("%s, size %d, width %d: unexpected error with %d bits left: %v",
+							name, size, width, remaining, err)
+					}
+					baOut.AddVar(v<<uint(64-bits), bits)
+					remaining -= bits
+				}
+				if !baIn.Equals(baOut) {
+					t.Errorf("%s, size %d, width %d: bits differ", name, size, width)
+				}
+				if left, err := nib.BitsRemaining(); err != nil || left != 0 {
+					t.Errorf("%s, size %d, width %d: expected 0 bits remaining, got %d and error `%v`",
+						name, size, width, left, err)
+				}
+				if _, err := nib.Nibble(1); err != io.EOF {
+					t.Errorf("%s, size %d, width %d: expected `io.EOF`, got `%v`", name, size, width, err)
+				}
+			}
+		}
+	}
+}
+
+func TestNibbleFunc(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xAB, 0xCD, 0xEF}))
+
+	// widths of 4, 8, 12 on successive calls
+	width := 0
+	widthFn := func() int {
+		width += 4
+		return width
+	}
+	expected := []uint64{0xA, 0xBC, 0xDEF}
+	for _, e := range expected {
+		if v, err := nib.NibbleFunc(widthFn); err != nil || v != e {
+			t.Errorf("expected %#x for %d bits, got %#x and error `%v`", e, width, v, err)
+		}
+	}
+
+	if _, err := nib.NibbleFunc(func() int { return 0 }); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, err := nib.NibbleFunc(func() int { return 65 }); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
+
+func benchmarkNibble(b *testing.B, bits int) {
+	data := make([]byte, 1<<20)
+	if _, err := rand.Read(data); err != nil {
+		panic(err)
+	}
+	count := len(data) * 8 / bits
+	b.SetBytes(int64(count * bits / 8))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		nib := nibs.New(bytes.NewReader(data))
+		for j := 0; j < count; j++ {
+			if _, err := nib.Nibble(bits); err != nil {
+				b.Fatalf("unexpected error: %v", err)
+			}
+		}
+	}
+}
+
+func BenchmarkNibble1(b *testing.B)  { benchmarkNibble(b, 1) }
+func BenchmarkNibble4(b *testing.B)  { benchmarkNibble(b, 4) }
+func BenchmarkNibble8(b *testing.B)  { benchmarkNibble(b, 8) }
+func BenchmarkNibble17(b *testing.B) { benchmarkNibble(b, 17) }
+func BenchmarkNibble64(b *testing.B) { benchmarkNibble(b, 64) }
@@ -0,0 +1,145 @@
+package nibs
+
+import "fmt"
+
+// ErrPadded is matched by the *PaddedError returned when the option
+// `WithZeroFillEOF` is used and a nibble is cut short by the end of the stream.
+var ErrPadded = newSentinel("nibble zero padded at EOF")
+
+// ErrAllocLimit is the error used when a length read from the stream would
+// need more memory than allowed by the option `WithMaxAlloc`.
+var ErrAllocLimit = newSentinel("allocation exceeds limit")
+
+// Option configures a Nibs created by `New`.
+type Option func(*Nibs)
+
+// WithZeroFillEOF returns an option that makes a read which runs out of input
+// part way through return a best effort value instead of io.EOF. The bits that
+// were present are consumed and returned left aligned, zero padded to the
+// requested width, along with a *PaddedError holding the number of bits that
+// were present. Subsequent reads return io.EOF as usual.
+//
+// This suits recovering the final record of a truncated log. Reads that find
+// no bits left at all are unaffected, and so are reads of several values at
+// once, such as `NibbleSignedSlice`, and methods that drain the stream, such
+// as `CountRemaining`, which return the complete values and leave a trailing
+// partial value unread.
+func WithZeroFillEOF() Option {
+	return func(n *Nibs) {
+		n.zeroFill = true
+	}
+}
+
+// WithPanicOnMisuse returns an option that makes `Nibble`, `Nibble8`,
+// `Nibble16` and `Nibble32` panic with a descriptive message when called with
+// `bits` out of range, rather than return nibs.ErrNibbleSize. An invalid size
+// is a bug in the caller, so this fails fast during development. The methods
+// built on them, and every other method that returns nibs.ErrNibbleSize for an
+// invalid argument or an error wrapping io.ErrShortBuffer for a destination
+// too short, panic likewise.
+func WithPanicOnMisuse() Option {
+	return func(n *Nibs) {
+		n.panicky = true
+	}
+}
+
+// WithMaxAlloc returns an option that limits the memory a read method may
+// allocate for a single result sized by a length read from the stream, such
+// as the values of `NibblePackedArray`, to `maxBytes` bytes. Larger lengths
+// return an error wrapping ErrAllocLimit before anything is allocated. This
+// guards against untrusted input declaring huge lengths. By default there is
+// no limit.
+func WithMaxAlloc(maxBytes int) Option {
+	return func(n *Nibs) {
+		n.maxAlloc = maxBytes
+	}
+}
+
+// WithMaxUnaryRun returns an option that limits the run of identical bits a
+// variable length code may contain, such as the leading 0 bits of
+// `NibbleExpGolombUnsigned` or the 0 bits of `NibbleFibonacci`, to `maxRun`
+// bits. Longer runs return an error wrapping ErrUnaryTooLong as soon as the
+// limit is passed, so corrupt or malicious input full of 0 bits fails fast
+// rather than being read to the end. By default, and for a `maxRun` of zero
+// or less, there is no limit.
+func WithMaxUnaryRun(maxRun int) Option {
+	return func(n *Nibs) {
+		if maxRun < 0 {
+			maxRun = 0
+		}
+		n.maxUnaryRun = maxRun
+	}
+}
+
+// WithLenientPadding returns an option that accepts padding with bits set,
+// such as the padding of `NibblePaddedRecord`, rather than return an error
+// wrapping ErrNonZeroPadding. Some writers leave padding uninitialised.
+func WithLenientPadding() Option {
+	return func(n *Nibs) {
+		n.lenientPadding = true
+	}
+}
+
+// WithAutoAlign returns an option that makes each field read skip to the next
+// multiple of `unitBits` bits after it, as for `AlignTo`, for formats where
+// every field starts on a boundary. A call of `Nibble`, `Nibble8`, `Nibble16`
+// or `Nibble32` reads a field. Methods that read a field in several parts, such
+// as `NibbleExpGolombUnsigned` and `NibbleRational`, align once after the whole
+// field, and methods that read a slice of values, such as `NibbleSignedSlice`,
+// align after each value.
+//
+// The bits skipped are consumed, so they count towards `BitsRead`, but they
+// are not checked; use `AlignTo` and read the padding to verify it. Nothing is
+// skipped if the stream ends before the boundary, and an error from the
+// reader while skipping is returned by the read. A `unitBits` of 1 or less
+// turns auto alignment off, and one greater than 64 is treated as 64.
+func WithAutoAlign(unitBits int) Option {
+	return func(n *Nibs) {
+		if unitBits > 64 {
+			unitBits = 64
+		}
+		if unitBits < 1 {
+			unitBits = 1
+		}
+		n.autoAlign = unitBits
+	}
+}
+
+// WithBufferSize returns an option that sets the size in bytes of the buffer
+// used to read from the underlying reader. The default is 64 bytes and sizes
+// below 16 bytes are raised to 16. A larger buffer means fewer reads, and
+// lets `Measure` rewind over longer parses.
+func WithBufferSize(size int) Option {
+	return func(n *Nibs) {
+		if size < minBufSize {
+			size = minBufSize
+		}
+		n.buf = make([]byte, size)
+	}
+}
+
+// checkAlloc returns an error wrapping ErrAllocLimit if `count` elements of
+// `size` bytes each exceed the limit set by `WithMaxAlloc`, if any.
+func (n *Nibs) checkAlloc(count uint64, size int) error {
+	if n.maxAlloc > 0 && count > uint64(n.maxAlloc/size) {
+		return n.wrap(fmt.Errorf("%w: %d elements of %d bytes, limit %d bytes", ErrAllocLimit, count, size, n.maxAlloc))
+	}
+	return nil
+}
+
+// PaddedError is the error returned with a zero padded value when the option
+// `WithZeroFillEOF` is used. errors.Is(err, nibs.ErrPadded) is true for a
+// *PaddedError.
+type PaddedError struct {
+	Bits  int // number of bits present in the stream
+	Width int // number of bits requested
+}
+
+func (e *PaddedError) Error() string {
+	return fmt.Sprintf("%v: %d of %d bits present", ErrPadded, e.Bits, e.Width)
+}
+
+// Is reports whether `target` is ErrPadded.
+func (e *PaddedError) Is(target error) bool {
+	return target == ErrPadded
+}
@@ -0,0 +1,351 @@
+package nibs_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestWithZeroFillEOF(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xAB, 0xCD}), nibs.WithZeroFillEOF())
+
+	if v, err := nib.Nibble(12); err != nil || v != 0xABC {
+		t.Fatalf("expected 0xABC, got %#x and error `%v`", v, err)
+	}
+
+	// 4 bits present, padded to 12
+	v, err := nib.Nibble(12)
+	if v != 0xD00 {
+		t.Errorf("expected 0xD00, got %#x", v)
+	}
+	var padded *nibs.PaddedError
+	if !errors.As(err, &padded) || padded.Bits != 4 || padded.Width != 12 {
+		t.Fatalf("expected *nibs.PaddedError with 4 of 12 bits, got `%v`", err)
+	}
+	if !errors.Is(err, nibs.ErrPadded) {
+		t.Errorf("expected error to match `nibs.ErrPadded`, got `%v`", err)
+	}
+
+	if left, err := nib.BitsRemaining(); err != nil || left != 0 {
+		t.Errorf("expected 0 bits remaining, got %d and error `%v`", left, err)
+	}
+	if nib.BitsRead() != 16 {
+		t.Errorf("expected 16 bits read, got %d", nib.BitsRead())
+	}
+	for i := 0; i < 2; i++ {
+		if v, err := nib.Nibble(12); err != io.EOF || v != 0 {
+			t.Errorf("expected 0 and `io.EOF`, got %#x and `%v`", v, err)
+		}
+	}
+}
+
+func TestWithZeroFillEOFDefault(t *testing.T) {
+	// without the option a short read consumes nothing and returns io.EOF
+	nib := nibs.New(bytes.NewReader([]byte{0xAB}))
+	if v, err := nib.Nibble(12); err != io.EOF || v != 0 {
+		t.Errorf("expected 0 and `io.EOF`, got %#x and `%v`", v, err)
+	}
+	if left, err := nib.BitsRemaining(); err != nil || left != 8 {
+		t.Errorf("expected 8 bits remaining, got %d and error `%v`", left, err)
+	}
+}
+
+func TestWithZeroFillEOFLimit(t *testing.T) {
+	// a split header ends at its bound rather than the end of the bytes
+	nib := nibs.New(bytes.NewReader([]byte{0xFF, 0xFF}), nibs.WithZeroFillEOF())
+	header, _, err := nib.SplitAt(6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	v, err := header.Nibble(8)
+	if v != 0xFC || !errors.Is(err, nibs.ErrPadded) {
+		t.Errorf("expected 0xFC and `nibs.ErrPadded`, got %#x and `%v`", v, err)
+	}
+	if _, err := header.Nibble(1); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestWithZeroFillEOFBatched(t *testing.T) {
+	// reads of several values return the complete values and leave the
+	// trailing partial value unread rather than padding it
+	nib := nibs.New(bytes.NewReader([]byte{0x12, 0x34, 0x56}), nibs.WithZeroFillEOF())
+	dst := make([]int64, 4)
+	if c, err := nib.NibbleSignedSlice(dst, 12); c != 2 || err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected 2 values and `io.ErrUnexpectedEOF`, got %d and `%v`", c, err)
+	}
+	if dst[0] != 0x123 || dst[1] != 0x456 {
+		t.Errorf("expected [0x123 0x456], got %#x", dst[:2])
+	}
+
+	tests := []struct {
+		name string
+		read func(*nibs.Nibs) error
+		left int
+	}{
+		{"NibbleGraySlice", func(n *nibs.Nibs) error {
+			c, err := n.NibbleGraySlice(make([]uint64, 4), 12)
+			return expectCount(c, 1, err, io.ErrUnexpectedEOF)
+		}, 8},
+		{"NibbleBases", func(n *nibs.Nibs) error {
+			c, err := n.NibbleBases(8, 4, make([]byte, 4), string(make([]byte, 256)), false)
+			return expectCount(c, 2, err, io.EOF)
+		}, 4},
+		{"ReadADPCM4", func(n *nibs.Nibs) error {
+			c, err := n.ReadADPCM4(make([]int, 8))
+			return expectCount(c, 5, err, io.ErrUnexpectedEOF)
+		}, 0},
+		{"CountRemaining", func(n *nibs.Nibs) error {
+			c, err := n.CountRemaining(12)
+			return expectCount(c, 1, err, nil)
+		}, 8},
+		{"MinWidth", func(n *nibs.Nibs) error {
+			w, err := n.MinWidth(12)
+			return expectCount(w, 12, err, nil)
+		}, 8},
+		{"ShannonEntropy", func(n *nibs.Nibs) error {
+			_, err := n.ShannonEntropy(12)
+			return err
+		}, 8},
+		{"RemainderHex", func(n *nibs.Nibs) error {
+			s, err := n.RemainderHex()
+			return expectCount(len(s), 4, err, nil)
+		}, 4},
+		{"RemainderBig", func(n *nibs.Nibs) error {
+			_, bits, err := n.RemainderBig()
+			return expectCount(bits, 20, err, nil)
+		}, 0},
+	}
+
+	for _, tt := range tests {
+		// 20 bits remain after the first 4
+		nib := nibs.New(bytes.NewReader([]byte{0xFF, 0x34, 0x56}), nibs.WithZeroFillEOF())
+		if _, err := nib.Nibble(4); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := tt.read(nib); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if left, err := nib.BitsRemaining(); err != nil || left != tt.left {
+			t.Errorf("%s: expected %d bits left unread, got %d and error `%v`", tt.name, tt.left, left, err)
+		}
+	}
+}
+
+// expectCount returns an error unless `c` and `err` are as expected.
+func expectCount(c, expected int, err, expectedErr error) error {
+	if c != expected || err != expectedErr {
+		return fmt.Errorf("expected %d and `%v`, got %d and `%v`", expected, expectedErr, c, err)
+	}
+	return nil
+}
+
+func TestWithPanicOnMisuse(t *testing.T) {
+	tests := []struct {
+		name string
+		read func(*nibs.Nibs) error
+	}{
+		{"Nibble", func(n *nibs.Nibs) error { _, err := n.Nibble(65); return err }},
+		{"Nibble8", func(n *nibs.Nibs) error { _, err := n.Nibble8(9); return err }},
+		{"Nibble16", func(n *nibs.Nibs) error { _, err := n.Nibble16(0); return err }},
+		{"Nibble32", func(n *nibs.Nibs) error { _, err := n.Nibble32(33); return err }},
+		{"NibbleBucket", func(n *nibs.Nibs) error { _, err := n.NibbleBucket(65, 3); return err }},
+		{"NibbleSignedMode", func(n *nibs.Nibs) error { _, err := n.NibbleSignedMode(0, nibs.OnesComplement); return err }},
+		{"NibbleSignedSlice", func(n *nibs.Nibs) error { _, err := n.NibbleSignedSlice(make([]int64, 2), 65); return err }},
+		{"NibbleGraySlice", func(n *nibs.Nibs) error { _, err := n.NibbleGraySlice(make([]uint64, 2), 0); return err }},
+		{"NibbleRangeFloat", func(n *nibs.Nibs) error { _, err := n.NibbleRangeFloat(65, 0, 1); return err }},
+		{"NibbleCalibrated", func(n *nibs.Nibs) error { _, err := n.NibbleCalibrated(0, false, 1, 0); return err }},
+		{"NibbleSignedFixed", func(n *nibs.Nibs) error { _, err := n.NibbleSignedFixed(8, 8); return err }},
+		{"NibbleFloatFields", func(n *nibs.Nibs) error { _, _, _, err := n.NibbleFloatFields(11, 53, 1023); return err }},
+		{"NibbleManchester", func(n *nibs.Nibs) error { _, err := n.NibbleManchester(65, true); return err }},
+		{"NibbleNRZI", func(n *nibs.Nibs) error { _, err := n.NibbleNRZI(0, false); return err }},
+		{"NibbleChunkedField", func(n *nibs.Nibs) error { _, _, err := n.NibbleChunkedField(0, 4); return err }},
+		{"NibbleBackref", func(n *nibs.Nibs) error { _, _, err := n.NibbleBackref(12, 65); return err }},
+		{"NibbleBases", func(n *nibs.Nibs) error {
+			_, err := n.NibbleBases(9, 1, make([]byte, 1), nibs.AlphabetACGT, false)
+			return err
+		}},
+		{"ReadMatrix", func(n *nibs.Nibs) error { return n.ReadMatrix(1, 1, 8, 0, make([]uint64, 1)) }},
+		{"ShannonEntropy", func(n *nibs.Nibs) error { _, err := n.ShannonEntropy(65); return err }},
+		{"MinWidth", func(n *nibs.Nibs) error { _, err := n.MinWidth(0); return err }},
+		{"CountRemaining", func(n *nibs.Nibs) error { _, err := n.CountRemaining(65); return err }},
+		{"Checksum1071", func(n *nibs.Nibs) error { _, err := n.Checksum1071(12); return err }},
+		{"AlignTo", func(n *nibs.Nibs) error { _, err := n.AlignTo(0); return err }},
+		{"NibbleTriStateSlice", func(n *nibs.Nibs) error { _, err := n.NibbleTriStateSlice(-1); return err }},
+		{"Skip", func(n *nibs.Nibs) error { return n.Skip(-1) }},
+		{"NibbleT", func(n *nibs.Nibs) error { _, err := nibs.NibbleT[uint16](n, 17); return err }},
+	}
+
+	for _, tt := range tests {
+		// without the option the error is returned
+		nib := nibs.New(bytes.NewReader([]byte{0xAB}))
+		if err := tt.read(nib); err != nibs.ErrNibbleSize {
+			t.Errorf("%s: expected `nibs.ErrNibbleSize`, got `%v`", tt.name, err)
+		}
+
+		nib = nibs.New(bytes.NewReader([]byte{0xAB}), nibs.WithPanicOnMisuse())
+		func() {
+			defer func() {
+				r := recover()
+				msg, ok := r.(string)
+				if !ok || !strings.Contains(msg, tt.name+" called with") {
+					t.Errorf("%s: expected a descriptive panic, got %v", tt.name, r)
+				}
+			}()
+			tt.read(nib)
+		}()
+	}
+
+	// valid reads are unaffected
+	nib := nibs.New(bytes.NewReader([]byte{0xAB}), nibs.WithPanicOnMisuse())
+	if v, err := nib.Nibble8(8); err != nil || v != 0xAB {
+		t.Errorf("expected 0xAB, got %#x and error `%v`", v, err)
+	}
+}
+
+func TestWithPanicOnMisuseArguments(t *testing.T) {
+	tests := []struct {
+		name string
+		read func(*nibs.Nibs) error
+		err  error // returned without the option
+	}{
+		{"NibbleMixed", func(n *nibs.Nibs) error { _, err := n.NibbleMixed(make([]uint64, 1), []int{4, 4}); return err }, io.ErrShortBuffer},
+		{"NibbleMixed", func(n *nibs.Nibs) error { _, err := n.NibbleMixed(make([]uint64, 3), []int{4, 4}); return err }, nibs.ErrNibbleSize},
+		{"ReadMatrix", func(n *nibs.Nibs) error { return n.ReadMatrix(-1, 2, 8, 1, nil) }, nibs.ErrNibbleSize},
+		{"ReadMatrix", func(n *nibs.Nibs) error { return n.ReadMatrix(2, 2, 2, 1, make([]uint64, 3)) }, io.ErrShortBuffer},
+		{"NibbleGrid", func(n *nibs.Nibs) error { _, err := n.NibbleGrid(2, -1); return err }, nibs.ErrNibbleSize},
+		{"NibbleBases", func(n *nibs.Nibs) error {
+			_, err := n.NibbleBases(2, 4, make([]byte, 3), nibs.AlphabetACGT, false)
+			return err
+		}, io.ErrShortBuffer},
+		{"NibbleDuration", func(n *nibs.Nibs) error { _, err := n.NibbleDuration(8, 0); return err }, nibs.ErrNibbleSize},
+		{"SplitAt", func(n *nibs.Nibs) error { _, _, err := n.SplitAt(-1); return err }, nibs.ErrNibbleSize},
+	}
+
+	for _, tt := range tests {
+		nib := nibs.New(bytes.NewReader([]byte{0xAB}))
+		if err := tt.read(nib); !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected `%v`, got `%v`", tt.name, tt.err, err)
+		}
+		if nib.BitsRead() != 0 {
+			t.Errorf("%s: expected no bits read, got %d", tt.name, nib.BitsRead())
+		}
+
+		nib = nibs.New(bytes.NewReader([]byte{0xAB}), nibs.WithPanicOnMisuse())
+		func() {
+			defer func() {
+				r := recover()
+				msg, ok := r.(string)
+				if !ok || !strings.Contains(msg, tt.name+" called with") {
+					t.Errorf("%s: expected a descriptive panic, got %v", tt.name, r)
+				}
+			}()
+			tt.read(nib)
+		}()
+	}
+}
+
+func TestWithAutoAlign(t *testing.T) {
+	// three 5-bit fields, each padded to a byte
+	nib := nibs.New(bytes.NewReader([]byte{0xA8, 0x77, 0xC8}), nibs.WithAutoAlign(8))
+
+	for i, expected := range []uint64{0x15, 0x0E, 0x19} {
+		if off := nib.BitsRead(); off != int64(i*8) {
+			t.Errorf("field %d: expected to start at bit %d, got %d", i, i*8, off)
+		}
+		v, err := nib.Nibble(5)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v != expected {
+			t.Errorf("field %d: expected %#x, got %#x", i, expected, v)
+		}
+	}
+	if _, err := nib.Nibble(1); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+
+	// aligning to 4 bits, with the last field ending the stream unaligned
+	nib = nibs.New(bytes.NewReader([]byte{0xAB, 0xCD}), nibs.WithAutoAlign(4))
+	for _, bits := range []int{3, 6, 2} {
+		if _, err := nib.Nibble(bits); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if nib.BitsRead() != 16 {
+		t.Errorf("expected 16 bits read, got %d", nib.BitsRead())
+	}
+}
+
+func TestWithAutoAlignMultiPart(t *testing.T) {
+	// a field read in several parts is aligned once, after the whole field
+	tests := []struct {
+		name     string
+		data     []byte
+		read     func(*nibs.Nibs) (uint64, error)
+		expected uint64
+	}{
+		{"NibbleExpGolombUnsigned", []byte{0x20, 0xAB}, func(n *nibs.Nibs) (uint64, error) {
+			return n.NibbleExpGolombUnsigned()
+		}, 3},
+		{"NibbleRational", []byte{0x34, 0xAB}, func(n *nibs.Nibs) (uint64, error) {
+			num, den, err := n.NibbleRational(3, 3)
+			return num<<4 | den, err
+		}, 0x15},
+		{"NibbleManchester", []byte{0x55, 0xAB}, func(n *nibs.Nibs) (uint64, error) {
+			return n.NibbleManchester(4, true)
+		}, 0xF},
+		{"NibbleFibonacci", []byte{0x60, 0xAB}, func(n *nibs.Nibs) (uint64, error) {
+			return n.NibbleFibonacci()
+		}, 2},
+	}
+
+	for _, tt := range tests {
+		nib := nibs.New(bytes.NewReader(tt.data), nibs.WithAutoAlign(8))
+		if v, err := tt.read(nib); err != nil || v != tt.expected {
+			t.Errorf("%s: expected %#x, got %#x and error `%v`", tt.name, tt.expected, v, err)
+			continue
+		}
+		if off := nib.BitsRead(); off%8 != 0 {
+			t.Errorf("%s: expected to end on a byte boundary, got bit %d", tt.name, off)
+		}
+		if v, err := nib.Nibble(8); err != nil || v != 0xAB {
+			t.Errorf("%s: expected 0xab after the field, got %#x and error `%v`", tt.name, v, err)
+		}
+	}
+
+	// an error from the reader while aligning is returned
+	dr := &droppingReader{r: bytes.NewReader([]byte{0x20, 0xAB, 0xCD}), drop: 1}
+	nib := nibs.New(dr, nibs.WithAutoAlign(16))
+	if v, err := nib.Nibble(4); v != 2 || !errors.Is(err, errDropped) {
+		t.Errorf("expected 2 and `errDropped`, got %#x and `%v`", v, err)
+	}
+}
+
+func TestWithAutoAlignSlice(t *testing.T) {
+	// each value of a slice is aligned, however many values are requested
+	expected := []int64{1, 3}
+	for _, size := range []int{1, 2, 4, 5} {
+		nib := nibs.New(bytes.NewReader([]byte{0x12, 0x34}), nibs.WithAutoAlign(8))
+		dst := make([]int64, size)
+		c, err := nib.NibbleSignedSlice(dst, 4)
+		if size > len(expected) {
+			if c != len(expected) || err != io.ErrUnexpectedEOF {
+				t.Fatalf("%d values: expected %d and `io.ErrUnexpectedEOF`, got %d and `%v`", size, len(expected), c, err)
+			}
+		} else if c != size || err != nil {
+			t.Fatalf("%d values: expected %d and no error, got %d and `%v`", size, size, c, err)
+		}
+		for i := 0; i < c; i++ {
+			if dst[i] != expected[i] {
+				t.Errorf("%d values: expected %v, got %v", size, expected[:c], dst[:c])
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package nibs
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Peek returns the next `bits` number of bits from the byte stream as a
+// uint64, like `Nibble`, without consuming them.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned. Errors are as for `Nibble`.
+func (n *Nibs) Peek(bits int) (uint64, error) {
+	var v uint64
+	_, err := n.Measure(func(n *Nibs) error {
+		var err error
+		v, err = n.nibble(bits)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+// MatchPrefix reports whether the next `bits` number of bits from the byte
+// stream equal `pattern`, without consuming them, for choosing between
+// alternatives in a grammar by what comes next. false is returned, with no
+// error, if fewer than `bits` bits remain in the stream.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned. Other errors are as for `Peek`.
+func (n *Nibs) MatchPrefix(pattern uint64, bits int) (bool, error) {
+	v, err := n.Peek(bits)
+	if errors.Is(err, io.EOF) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return v == pattern, nil
+}
+
+// PeekString returns the next `bits` number of bits from the byte stream as a
+// string of '0' and '1' characters, without consuming them. This suits
+// exploring an unknown format interactively.
+//
+// `bits` may be more than 64 but must fit in the read buffer, as for
+// `Measure`, otherwise an error wrapping ErrRewindLimit is returned. A `bits`
+// less than 1 returns nibs.ErrNibbleSize, and io.EOF is returned if fewer
+// than `bits` bits remain in the stream.
+func (n *Nibs) PeekString(bits int) (string, error) {
+	if bits < 1 {
+		return "", n.sizeError("PeekString", bits, len(n.buf)*8)
+	}
+
+	var sb strings.Builder
+	sb.Grow(bits)
+	_, err := n.Measure(func(n *Nibs) error {
+		for left := bits; left > 0; {
+			size := 64
+			if left < size {
+				size = left
+			}
+			v, err := n.nibble(size)
+			if err != nil {
+				return err
+			}
+			s := strconv.FormatUint(v, 2)
+			sb.WriteString(strings.Repeat("0", size-len(s)))
+			sb.WriteString(s)
+			left -= size
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,116 @@
+package nibs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestPeek(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xAB, 0xCD}))
+	if _, err := nib.Nibble(3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if v, err := nib.Peek(8); err != nil || v != 0x5E {
+			t.Errorf("expected 0x5e, got %#x and error `%v`", v, err)
+		}
+	}
+	if nib.BitsRead() != 3 {
+		t.Errorf("expected position unchanged at 3, got %d", nib.BitsRead())
+	}
+	if v, err := nib.Nibble(13); err != nil || v != 0x0BCD {
+		t.Errorf("expected 0xbcd, got %#x and error `%v`", v, err)
+	}
+
+	if _, err := nib.Peek(1); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+	if _, err := nib.Peek(65); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
+
+func TestMatchPrefix(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(PackBits("10110 011")))
+
+	if ok, err := nib.MatchPrefix(0x16, 5); err != nil || !ok {
+		t.Errorf("expected 10110 to match, got %v and error `%v`", ok, err)
+	}
+	if ok, err := nib.MatchPrefix(0x17, 5); err != nil || ok {
+		t.Errorf("expected 10111 not to match, got %v and error `%v`", ok, err)
+	}
+	if nib.BitsRead() != 0 {
+		t.Errorf("expected position unchanged at 0, got %d", nib.BitsRead())
+	}
+
+	if _, err := nib.Nibble(5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ok, err := nib.MatchPrefix(0x3, 3); err != nil || !ok {
+		t.Errorf("expected 011 to match, got %v and error `%v`", ok, err)
+	}
+	// fewer bits than the pattern remain
+	if ok, err := nib.MatchPrefix(0x6, 4); err != nil || ok {
+		t.Errorf("expected no match near EOF, got %v and error `%v`", ok, err)
+	}
+
+	if _, err := nib.MatchPrefix(0, 0); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	nib = nibs.New(NewFlakyReader(bytes.NewReader(make([]byte, 8)), 0))
+	if _, err := nib.MatchPrefix(0, 5); !errors.Is(err, ErrFlaky) {
+		t.Errorf("expected `ErrFlaky`, got `%v`", err)
+	}
+}
+
+func TestPeekString(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xAB, 0xCD, 0xEF}))
+	if _, err := nib.Nibble(2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if s, err := nib.PeekString(11); err != nil || s != "10101111001" {
+		t.Errorf("expected \"10101111001\", got %q and error `%v`", s, err)
+	}
+	if nib.BitsRead() != 2 {
+		t.Errorf("expected position unchanged at 2, got %d", nib.BitsRead())
+	}
+	if v, err := nib.Nibble(11); err != nil || v != 0x579 {
+		t.Errorf("expected 0x579, got %#x and error `%v`", v, err)
+	}
+
+	if _, err := nib.PeekString(12); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+	if _, err := nib.PeekString(0); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
+
+func TestPeekStringLong(t *testing.T) {
+	b := bytes.Repeat([]byte{0xF0}, 100)
+	nib := nibs.New(bytes.NewReader(b))
+
+	// more than 64 bits, within the 64 byte buffer
+	s, err := nib.PeekString(200)
+	if err != nil || s != strings.Repeat("11110000", 25) {
+		t.Errorf("expected 200 bits of 11110000, got %q and error `%v`", s, err)
+	}
+
+	// more than the buffer holds
+	if _, err := nib.PeekString(520); !errors.Is(err, nibs.ErrRewindLimit) {
+		t.Errorf("expected `nibs.ErrRewindLimit`, got `%v`", err)
+	}
+	nib = nibs.New(bytes.NewReader(b), nibs.WithBufferSize(100))
+	if s, err := nib.PeekString(800); err != nil || len(s) != 800 {
+		t.Errorf("expected 800 bits, got %d and error `%v`", len(s), err)
+	}
+}
@@ -0,0 +1,215 @@
+package nibs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Nibbler is the set of read methods shared by *Nibs, *Recorder and *Replayer,
+// so a decoder written against it can be recorded and replayed.
+type Nibbler interface {
+	Nibble(bits int) (uint64, error)
+	Nibble8(bits int) (uint8, error)
+	Nibble16(bits int) (uint16, error)
+	Nibble32(bits int) (uint32, error)
+	BitsRead() int64
+}
+
+var (
+	_ Nibbler = (*Nibs)(nil)
+	_ Nibbler = (*Recorder)(nil)
+	_ Nibbler = (*Replayer)(nil)
+)
+
+// ErrReplayMismatch is the error used when a *Replayer is asked for an
+// operation other than the next one in its log, meaning the decoder being
+// replayed has diverged from the recorded session.
+var ErrReplayMismatch = newSentinel("operation does not match replay log")
+
+// replayErrors are the errors restored by identity when replayed. Any other
+// recorded error is replayed as a new error with the same text.
+var replayErrors = []error{
+	io.EOF, io.ErrUnexpectedEOF, io.ErrNoProgress,
+	ErrNibbleSize, ErrUnknown, ErrOverflow,
+}
+
+// Recorder wraps a *Nibs and logs each read operation to a sink, so the
+// session can be served again later by a *Replayer without the original data.
+//
+// The log has one line per operation, holding the absolute bit offset before
+// the operation, the method name, the width requested and either "=" followed
+// by the value returned in hexadecimal, or "!" followed by the error text:
+//
+//	0 Nibble 12 = abc
+//	12 Nibble8 4 = d
+//	16 Nibble 8 ! EOF
+type Recorder struct {
+	n    *Nibs
+	sink io.Writer
+	err  error
+}
+
+// NewRecorder returns a new Recorder which reads from `n` and logs each
+// operation to `sink`.
+func NewRecorder(n *Nibs, sink io.Writer) *Recorder {
+	return &Recorder{n: n, sink: sink}
+}
+
+// Err returns the first error writing to the sink, if any. Reads are not
+// affected by errors writing the log.
+func (r *Recorder) Err() error {
+	return r.err
+}
+
+// Nibble reads from the wrapped *Nibs and records the operation.
+func (r *Recorder) Nibble(bits int) (uint64, error) {
+	offset := r.n.offset()
+	v, err := r.n.Nibble(bits)
+	r.record(offset, "Nibble", bits, v, err)
+	return v, err
+}
+
+// Nibble8 reads from the wrapped *Nibs and records the operation.
+func (r *Recorder) Nibble8(bits int) (uint8, error) {
+	offset := r.n.offset()
+	v, err := r.n.Nibble8(bits)
+	r.record(offset, "Nibble8", bits, uint64(v), err)
+	return v, err
+}
+
+// Nibble16 reads from the wrapped *Nibs and records the operation.
+func (r *Recorder) Nibble16(bits int) (uint16, error) {
+	offset := r.n.offset()
+	v, err := r.n.Nibble16(bits)
+	r.record(offset, "Nibble16", bits, uint64(v), err)
+	return v, err
+}
+
+// Nibble32 reads from the wrapped *Nibs and records the operation.
+func (r *Recorder) Nibble32(bits int) (uint32, error) {
+	offset := r.n.offset()
+	v, err := r.n.Nibble32(bits)
+	r.record(offset, "Nibble32", bits, uint64(v), err)
+	return v, err
+}
+
+// BitsRead returns the number of bits read from the wrapped *Nibs so far.
+func (r *Recorder) BitsRead() int64 {
+	return r.n.BitsRead()
+}
+
+func (r *Recorder) record(offset int64, method string, bits int, v uint64, err error) {
+	if r.err != nil {
+		return
+	}
+	var line string
+	if err != nil {
+		text := strings.ReplaceAll(err.Error(), "\n", " ")
+		line = fmt.Sprintf("%d %s %d ! %s\n", offset, method, bits, text)
+	} else {
+		line = fmt.Sprintf("%d %s %d = %x\n", offset, method, bits, v)
+	}
+	_, r.err = io.WriteString(r.sink, line)
+}
+
+// Replayer serves the read operations logged by a *Recorder, returning the
+// same values and errors in the same order. See `Recorder` for the log format.
+//
+// Each operation must match the method and width of the next one in the log,
+// otherwise an error wrapping ErrReplayMismatch is returned. Errors recorded
+// as io.EOF, io.ErrUnexpectedEOF, io.ErrNoProgress, ErrNibbleSize, ErrUnknown
+// or ErrOverflow are replayed as those errors; others are replayed with the
+// same text only.
+type Replayer struct {
+	scanner *bufio.Scanner
+	line    int
+	offset  int64
+}
+
+// NewReplayer returns a new Replayer which serves the operations logged in `r`.
+func NewReplayer(r io.Reader) *Replayer {
+	return &Replayer{scanner: bufio.NewScanner(r)}
+}
+
+// Nibble returns the value or error recorded for the next operation.
+func (r *Replayer) Nibble(bits int) (uint64, error) {
+	return r.replay("Nibble", bits)
+}
+
+// Nibble8 returns the value or error recorded for the next operation.
+func (r *Replayer) Nibble8(bits int) (uint8, error) {
+	v, err := r.replay("Nibble8", bits)
+	return uint8(v), err
+}
+
+// Nibble16 returns the value or error recorded for the next operation.
+func (r *Replayer) Nibble16(bits int) (uint16, error) {
+	v, err := r.replay("Nibble16", bits)
+	return uint16(v), err
+}
+
+// Nibble32 returns the value or error recorded for the next operation.
+func (r *Replayer) Nibble32(bits int) (uint32, error) {
+	v, err := r.replay("Nibble32", bits)
+	return uint32(v), err
+}
+
+// BitsRead returns the number of bits read in the recorded session as of the
+// operations replayed so far.
+func (r *Replayer) BitsRead() int64 {
+	return r.offset
+}
+
+func (r *Replayer) replay(method string, bits int) (uint64, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("%w: %s(%d) after end of log", ErrReplayMismatch, method, bits)
+	}
+	r.line++
+
+	fields := strings.SplitN(r.scanner.Text(), " ", 5)
+	if len(fields) != 5 {
+		return 0, r.invalid()
+	}
+	offset, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, r.invalid()
+	}
+	recBits, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return 0, r.invalid()
+	}
+	if fields[1] != method || recBits != bits {
+		return 0, fmt.Errorf("%w: %s(%d) at line %d, recorded %s(%d)",
+			ErrReplayMismatch, method, bits, r.line, fields[1], recBits)
+	}
+
+	switch fields[3] {
+	case "=":
+		v, err := strconv.ParseUint(fields[4], 16, 64)
+		if err != nil {
+			return 0, r.invalid()
+		}
+		r.offset = offset + int64(bits)
+		return v, nil
+	case "!":
+		r.offset = offset
+		for _, known := range replayErrors {
+			if known.Error() == fields[4] {
+				return 0, known
+			}
+		}
+		return 0, errors.New(fields[4])
+	}
+	return 0, r.invalid()
+}
+
+func (r *Replayer) invalid() error {
+	return fmt.Errorf("invalid replay log line %d: %q", r.line, r.scanner.Text())
+}
@@ -0,0 +1,123 @@
+package nibs_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+)
+
+type record struct {
+	kind  uint8
+	value uint64
+	flags uint16
+}
+
+// decodeRecords is a small decoder written against nibs.Nibbler. It reads
+// records until an error, which it returns along with the records read.
+func decodeRecords(nb nibs.Nibbler) ([]record, error) {
+	var recs []record
+	for {
+		kind, err := nb.Nibble8(3)
+		if err != nil {
+			return recs, err
+		}
+		value, err := nb.Nibble(int(kind)*8 + 5)
+		if err != nil {
+			return recs, err
+		}
+		flags, err := nb.Nibble16(11)
+		if err != nil {
+			return recs, err
+		}
+		if _, err := nb.Nibble32(int(kind) + 1); err != nil {
+			return recs, err
+		}
+		recs = append(recs, record{kind: kind, value: value, flags: flags})
+	}
+}
+
+func TestRecorderReplayer(t *testing.T) {
+	b := make([]byte, 4096)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	log := &bytes.Buffer{}
+	rec := nibs.NewRecorder(nibs.New(bytes.NewReader(b)), log)
+	expected, expectedErr := decodeRecords(rec)
+	if rec.Err() != nil {
+		t.Fatalf("unexpected error recording: %v", rec.Err())
+	}
+	if expectedErr != io.EOF {
+		t.Fatalf("expected `io.EOF` from decoder, got `%v`", expectedErr)
+	}
+
+	rep := nibs.NewReplayer(bytes.NewReader(log.Bytes()))
+	recs, err := decodeRecords(rep)
+	if err != expectedErr {
+		t.Errorf("expected error `%v` from replay, got `%v`", expectedErr, err)
+	}
+	if len(recs) != len(expected) {
+		t.Fatalf("expected %d records from replay, got %d", len(expected), len(recs))
+	}
+	for i := range recs {
+		if recs[i] != expected[i] {
+			t.Errorf("record %d: expected %+v, got %+v", i, expected[i], recs[i])
+		}
+	}
+	if rep.BitsRead() != rec.BitsRead() {
+		t.Errorf("expected %d bits read, got %d", rec.BitsRead(), rep.BitsRead())
+	}
+}
+
+func TestRecorderFormat(t *testing.T) {
+	log := &bytes.Buffer{}
+	rec := nibs.NewRecorder(nibs.New(bytes.NewReader([]byte{0xAB, 0xCD})), log)
+	rec.Nibble(12)
+	rec.Nibble8(4)
+	rec.Nibble(8)
+	rec.Nibble16(17)
+
+	expected := "0 Nibble 12 = abc\n" +
+		"12 Nibble8 4 = d\n" +
+		"16 Nibble 8 ! EOF\n" +
+		"16 Nibble16 17 ! invalid nibble size\n"
+	if log.String() != expected {
+		t.Errorf("expected log:\n%s\ngot:\n%s", expected, log.String())
+	}
+}
+
+func TestReplayerErrors(t *testing.T) {
+	log := "0 Nibble 12 = abc\n" +
+		"12 Nibble 4 ! unexpected EOF\n" +
+		"12 Nibble 4 ! disk on fire\n" +
+		"12 Nibble 4 = d\n"
+	rep := nibs.NewReplayer(bytes.NewReader([]byte(log)))
+
+	if v, err := rep.Nibble(12); err != nil || v != 0xABC {
+		t.Errorf("expected 0xABC, got %#x and error `%v`", v, err)
+	}
+	if _, err := rep.Nibble(4); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected `io.ErrUnexpectedEOF`, got `%v`", err)
+	}
+	if _, err := rep.Nibble(4); err == nil || err.Error() != "disk on fire" {
+		t.Errorf("expected `disk on fire`, got `%v`", err)
+	}
+
+	// decoder diverges from the recording
+	if _, err := rep.Nibble8(4); !errors.Is(err, nibs.ErrReplayMismatch) {
+		t.Errorf("expected `nibs.ErrReplayMismatch`, got `%v`", err)
+	}
+	if _, err := rep.Nibble(4); !errors.Is(err, nibs.ErrReplayMismatch) {
+		t.Errorf("expected `nibs.ErrReplayMismatch` after end of log, got `%v`", err)
+	}
+
+	rep = nibs.NewReplayer(bytes.NewReader([]byte("0 Nibble 12 = xyz\n")))
+	if _, err := rep.Nibble(12); err == nil || errors.Is(err, nibs.ErrReplayMismatch) {
+		t.Errorf("expected invalid log error, got `%v`", err)
+	}
+}
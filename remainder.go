@@ -0,0 +1,68 @@
+package nibs
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// RemainderHex reads the remainder of the stream in whole bytes and returns
+// them hex encoded, which is convenient for golden file tests of decoders.
+// The bytes are read from the current position, which need not be byte
+// aligned.
+//
+// The stream is drained. Trailing bits too few to make a full byte are not
+// included and are left unread (see `BitsRemaining`).
+func (n *Nibs) RemainderHex() (string, error) {
+	defer n.withoutZeroFill()()
+
+	var buf []byte
+	for {
+		b, err := n.Nibble8(8)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		buf = append(buf, b)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// RemainderBig reads every remaining bit of the stream, however many, and
+// returns them as an unsigned big.Int with the first bit read as the most
+// significant, along with the number of bits read. The count allows leading
+// zero bits, which the value does not record, to be recovered. The bits are
+// read from the current position, which need not be byte aligned.
+//
+// The stream is drained. A value of 0 and a count of 0 are returned if no bits
+// remain.
+func (n *Nibs) RemainderBig() (*big.Int, int, error) {
+	defer n.withoutZeroFill()()
+
+	val := new(big.Int)
+	word := new(big.Int)
+	count := 0
+	for {
+		size := 64
+		v, err := n.Nibble(size)
+		if errors.Is(err, io.EOF) {
+			left, rerr := n.BitsRemaining()
+			if rerr != nil {
+				return nil, 0, rerr
+			}
+			if left == 0 {
+				return val, count, nil
+			}
+			size = left
+			v, err = n.Nibble(size)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		val.Lsh(val, uint(size)).Or(val, word.SetUint64(v))
+		count += size
+	}
+}
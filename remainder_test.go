@@ -0,0 +1,82 @@
+package nibs_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestRemainderHex(t *testing.T) {
+	const size = 300
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	nib := nibs.New(bytes.NewReader(b))
+
+	// skip the first 2 bytes
+	if _, err := nib.Nibble(16); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s, err := nib.RemainderHex()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := hex.EncodeToString(b[2:]); s != expected {
+		t.Errorf("expected %s, got %s", expected, s)
+	}
+}
+
+func TestRemainderHexPartial(t *testing.T) {
+	// after 4 bits, 2 whole bytes and 4 trailing bits remain
+	nib := nibs.New(bytes.NewReader(PackBits("1010 11011110 10101101 1011")))
+	if _, err := nib.Nibble(4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s, err := nib.RemainderHex()
+	if err != nil || s != "dead" {
+		t.Errorf("expected dead, got %s and error `%v`", s, err)
+	}
+	if r, err := nib.BitsRemaining(); err != nil || r != 4 {
+		t.Errorf("expected 4 bits remaining, got %d and error `%v`", r, err)
+	}
+}
+
+func TestRemainderBig(t *testing.T) {
+	// 3 bits, then a 200-bit value with 7 leading zero bits, then 5 trailing bits
+	tail := "0000000" + strings.Repeat("1011", 48) + "1" + "01101"
+	nib := nibs.New(bytes.NewReader(PackBits("101" + tail)))
+	if _, err := nib.Nibble(3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	v, count, err := nib.RemainderBig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != len(tail) {
+		t.Errorf("expected %d bits, got %d", len(tail), count)
+	}
+	expected, _ := new(big.Int).SetString(tail, 2)
+	if v.Cmp(expected) != 0 {
+		t.Errorf("expected %x, got %x", expected, v)
+	}
+	// leading zeros recovered from the count
+	if s := fmt.Sprintf("%0*b", count, v); s != tail {
+		t.Errorf("expected bits %s, got %s", tail, s)
+	}
+
+	// drained
+	v, count, err = nib.RemainderBig()
+	if err != nil || count != 0 || v.Sign() != 0 {
+		t.Errorf("expected 0 bits, got %d bits of %x and error `%v`", count, v, err)
+	}
+}
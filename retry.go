@@ -0,0 +1,73 @@
+package nibs
+
+// ErrRetryLimit is the error used when `RetryField` cannot rewind because the
+// field has grown larger than the retry buffer.
+var ErrRetryLimit = newSentinel("field exceeds retry buffer")
+
+// ErrNoRetryBuffer is the error returned by `RetryField` when the option
+// `WithRetryBuffer` is not used.
+var ErrNoRetryBuffer = newSentinel("RetryField requires the WithRetryBuffer option")
+
+// retryPoint is the position and state saved at the start of the current
+// field, restored by `RetryField`.
+type retryPoint struct {
+	offset int64 // absolute bit offset, or -1 once no longer buffered
+	state
+}
+
+// WithRetryBuffer returns an option that allows `RetryField` to recover from
+// an error returned by the underlying reader, such as a dropped network
+// connection, by rewinding to the start of the field being read. The field
+// starts at the most recent call to `Enter`, or at the start of the stream if
+// it has not been called.
+//
+// The bytes of the field are kept in the read buffer, which is `bytes` bytes,
+// raised to 16 if smaller. A field that outgrows the buffer cannot be retried,
+// but reading continues as usual.
+func WithRetryBuffer(bytes int) Option {
+	return func(n *Nibs) {
+		WithBufferSize(bytes)(n)
+		n.retry = true
+	}
+}
+
+// RetryField clears any error returned by the underlying reader and rewinds to
+// the start of the field being read, so the field can be read again once the
+// caller has repaired the reader, for example by reconnecting. Bytes already
+// read from the reader are not requested again. State affected by reads since
+// the start of the field, such as the NRZI line level, the running Fletcher-16
+// checksum, the value trace and the previous values of delta coded series, is
+// restored. The field, and any fields entered within it, are left, so the
+// caller reads the field again from the code that entered it.
+//
+// ErrRetryLimit is returned, and nothing is changed, if the field no longer
+// fits in the buffer set by `WithRetryBuffer`. ErrNoRetryBuffer is returned
+// if the option is not used, and ErrWithinMeasure if RetryField is called
+// from within `Measure`.
+func (n *Nibs) RetryField() error {
+	if !n.retry {
+		return ErrNoRetryBuffer
+	}
+	if n.mark >= 0 {
+		return ErrWithinMeasure
+	}
+	p := &n.retryAt
+	if p.offset < 0 {
+		return ErrRetryLimit
+	}
+
+	n.err = nil
+	n.pos = int(p.offset - n.base)
+	p.restore(n)
+	return nil
+}
+
+// markRetry starts a new field at the current position, before any field
+// being entered, if the option `WithRetryBuffer` is used.
+func (n *Nibs) markRetry() {
+	if !n.retry {
+		return
+	}
+	n.retryAt.offset = n.offset()
+	n.retryAt.save(n)
+}
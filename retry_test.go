@@ -0,0 +1,153 @@
+package nibs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+)
+
+var errDropped = errors.New("connection dropped")
+
+// droppingReader returns errDropped once `drop` bytes have been read, until
+// repaired.
+type droppingReader struct {
+	r        io.Reader
+	drop     int
+	read     int
+	repaired bool
+}
+
+func (d *droppingReader) Read(p []byte) (int, error) {
+	if !d.repaired {
+		if d.read >= d.drop {
+			return 0, errDropped
+		}
+		if left := d.drop - d.read; len(p) > left {
+			p = p[:left]
+		}
+	}
+	c, err := d.r.Read(p)
+	d.read += c
+	return c, err
+}
+
+// readRecord reads a record of a 4-bit type, a 12-bit length and a 32-bit value.
+func readRecord(nib *nibs.Nibs) (uint64, error) {
+	nib.Enter("record")
+	defer nib.Leave()
+	if _, err := nib.Nibble(4); err != nil {
+		return 0, err
+	}
+	if _, err := nib.Nibble(12); err != nil {
+		return 0, err
+	}
+	return nib.Nibble(32)
+}
+
+func TestRetryField(t *testing.T) {
+	// a 2 byte header, then a record, split by a dropped connection
+	b := []byte{0xFF, 0xFF, 0x10, 0x04, 0xDE, 0xAD, 0xBE, 0xEF}
+	dr := &droppingReader{r: bytes.NewReader(b), drop: 5}
+	nib := nibs.New(dr, nibs.WithRetryBuffer(64))
+
+	if v, err := nib.Nibble(16); err != nil || v != 0xFFFF {
+		t.Fatalf("expected header 0xffff, got %#x and error `%v`", v, err)
+	}
+	if _, err := readRecord(nib); !errors.Is(err, errDropped) {
+		t.Fatalf("expected `errDropped`, got `%v`", err)
+	}
+
+	// the record fails again until the reader is repaired
+	if err := nib.RetryField(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := readRecord(nib); !errors.Is(err, errDropped) {
+		t.Errorf("expected `errDropped` before repair, got `%v`", err)
+	}
+
+	dr.repaired = true
+	if err := nib.RetryField(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, err := readRecord(nib); err != nil || v != 0xDEADBEEF {
+		t.Errorf("expected 0xdeadbeef, got %#x and error `%v`", v, err)
+	}
+	if nib.BitsRead() != int64(len(b))*8 {
+		t.Errorf("expected %d bits read, got %d", len(b)*8, nib.BitsRead())
+	}
+}
+
+func TestRetryFieldSeries(t *testing.T) {
+	// retrying a field leaves the series as it was at the start of the field
+	b := []byte{0x12, 0x34, 0x56}
+	for _, tt := range seriesReads {
+		nib := nibs.New(bytes.NewReader(b), nibs.WithRetryBuffer(64))
+		tt.read(nib)
+		nib.Enter("value")
+		expected, err := tt.read(nib)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if err := nib.RetryField(); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if v, err := tt.read(nib); err != nil || v != expected {
+			t.Errorf("%s: expected %#x after retrying, got %#x and error `%v`", tt.name, expected, v, err)
+		}
+	}
+}
+
+func TestRetryFieldLimit(t *testing.T) {
+	// a field larger than the buffer cannot be retried
+	dr := &droppingReader{r: bytes.NewReader(make([]byte, 64)), drop: 40}
+	nib := nibs.New(dr, nibs.WithRetryBuffer(16))
+	nib.Enter("blob")
+	for i := 0; i < 5; i++ {
+		if _, err := nib.Nibble(64); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := nib.Nibble(64); !errors.Is(err, errDropped) {
+		t.Fatalf("expected `errDropped`, got `%v`", err)
+	}
+	if err := nib.RetryField(); err != nibs.ErrRetryLimit {
+		t.Errorf("expected `nibs.ErrRetryLimit`, got `%v`", err)
+	}
+
+	// a skip that seeks past the buffer leaves the field behind
+	nib = nibs.New(bytes.NewReader(make([]byte, 4096)), nibs.WithRetryBuffer(64))
+	nib.Enter("skipped")
+	if err := nib.Skip(8000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := nib.RetryField(); err != nibs.ErrRetryLimit {
+		t.Errorf("expected `nibs.ErrRetryLimit` after seeking, got `%v`", err)
+	}
+	if _, err := nib.Nibble(8); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if read := nib.BitsRead(); read != 8008 {
+		t.Errorf("expected 8008 bits read, got %d", read)
+	}
+
+	// without the option
+	nib = nibs.New(bytes.NewReader(nil))
+	if err := nib.RetryField(); err != nibs.ErrNoRetryBuffer {
+		t.Errorf("expected `nibs.ErrNoRetryBuffer` without option, got `%v`", err)
+	}
+
+	// nor within Measure
+	nib = nibs.New(bytes.NewReader(nil), nibs.WithRetryBuffer(64))
+	nib.Measure(func(n *nibs.Nibs) error {
+		if err := n.RetryField(); err != nibs.ErrWithinMeasure {
+			t.Errorf("expected `nibs.ErrWithinMeasure`, got `%v`", err)
+		}
+		if err := n.Rewind(); err != nibs.ErrWithinMeasure {
+			t.Errorf("expected `nibs.ErrWithinMeasure` from Rewind, got `%v`", err)
+		}
+		return nil
+	})
+}
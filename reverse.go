@@ -0,0 +1,37 @@
+package nibs
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// ReverseBits returns the low `width` bits of `value` in reverse order, so the
+// left-most bit of the field becomes the right-most. Bits above `width` are
+// ignored. This converts a value read with `Nibble` from a field stored least
+// significant bit first, as in many serial and radio protocols.
+//
+// ReverseBits panics if `width` is not in the range 1 to 64 inclusive.
+func ReverseBits(value uint64, width int) uint64 {
+	if width < 1 || width > 64 {
+		panic(fmt.Sprintf("nibs: ReverseBits called with %d bits; must be 1 to 64", width))
+	}
+	return bits.Reverse64(value) >> uint(64-width)
+}
+
+// NibbleBothOrders reads `bits` number of bits from the byte stream and returns
+// the value both as `Nibble` would, with the first bit read as the most
+// significant, and with the first bit read as the least significant, as
+// `ReverseBits` would convert it. This helps when exploring a format whose bit
+// order is not known.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+//
+// See `Nibble` method for details.
+func (n *Nibs) NibbleBothOrders(bits int) (msbFirst, lsbFirst uint64, err error) {
+	v, err := n.Nibble(bits)
+	if err != nil {
+		return 0, 0, err
+	}
+	return v, ReverseBits(v, bits), nil
+}
@@ -0,0 +1,71 @@
+package nibs_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestReverseBits(t *testing.T) {
+	tests := []struct {
+		value    uint64
+		width    int
+		expected uint64
+	}{
+		{value: 0x1, width: 4, expected: 0x8},
+		{value: 0x6, width: 4, expected: 0x6},
+		{value: 0xE, width: 4, expected: 0x7},
+		{value: 0x01, width: 8, expected: 0x80},
+		{value: 0xB4, width: 8, expected: 0x2D},
+		{value: 0xF0, width: 8, expected: 0x0F},
+		{value: 0x0001, width: 13, expected: 0x1000},
+		{value: 0x1234, width: 13, expected: 0x0589},
+		{value: 0xFFFF_0123, width: 13, expected: 0x1890},
+		{value: 1, width: 1, expected: 1},
+		{value: 0x0123_4567_89AB_CDEF, width: 64, expected: 0xF7B3_D591_E6A2_C480},
+	}
+	for _, tt := range tests {
+		if v := nibs.ReverseBits(tt.value, tt.width); v != tt.expected {
+			t.Errorf("expected %#x reversing %#x in %d bits, got %#x", tt.expected, tt.value, tt.width, v)
+		}
+	}
+}
+
+func TestReverseBitsPanics(t *testing.T) {
+	for _, width := range []int{0, 65} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic for %d bits", width)
+				}
+			}()
+			nibs.ReverseBits(1, width)
+		}()
+	}
+}
+
+func TestNibbleBothOrders(t *testing.T) {
+	// 0xB4 then the 13 bits 1 0010 0011 0100
+	nib := nibs.New(bytes.NewReader([]byte{0xB4, 0x91, 0xA0}))
+
+	msb, lsb, err := nib.NibbleBothOrders(8)
+	if err != nil || msb != 0xB4 || lsb != 0x2D {
+		t.Errorf("expected 0xb4 and 0x2d, got %#x, %#x and error `%v`", msb, lsb, err)
+	}
+	msb, lsb, err = nib.NibbleBothOrders(13)
+	if err != nil || msb != 0x1234 || lsb != 0x0589 {
+		t.Errorf("expected 0x1234 and 0x589, got %#x, %#x and error `%v`", msb, lsb, err)
+	}
+	if nibs.ReverseBits(lsb, 13) != msb {
+		t.Errorf("expected %#x and %#x to be reverses in 13 bits", msb, lsb)
+	}
+
+	if _, _, err := nib.NibbleBothOrders(65); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, _, err := nib.NibbleBothOrders(4); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
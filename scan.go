@@ -0,0 +1,116 @@
+package nibs
+
+import "fmt"
+
+// ErrScanLimit is the error used when a scan reads more bits than allowed by
+// the option `WithScanLimit` without finding what it scans for.
+var ErrScanLimit = newSentinel("scan limit reached")
+
+// defaultScanLimit is the number of bits a scan may skip, 1 MiB.
+const defaultScanLimit = 8 << 20
+
+// WithScanLimit returns an option that limits the number of bits a scan, such
+// as `FindPattern`, may skip before giving up with an error wrapping
+// ErrScanLimit. This bounds the work done on input that never matches. The
+// default is 8388608 bits (1 MiB), and `bits` of zero or less means no limit.
+func WithScanLimit(bits int64) Option {
+	return func(n *Nibs) {
+		if bits < 0 {
+			bits = 0
+		}
+		n.scanLimit = bits
+	}
+}
+
+// FindPattern reads bits one at a time until the last `patternBits` bits read
+// equal the low `patternBits` bits of `pattern`, such as a sync word, and
+// returns the number of bits skipped before the match started. The matched
+// bits are consumed, so the next read starts just after them.
+//
+// `patternBits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned. io.EOF is returned if the stream ends
+// without a match, and an error wrapping ErrScanLimit if more bits than
+// allowed by `WithScanLimit` are skipped. Bits read while scanning are
+// consumed in either case.
+func (n *Nibs) FindPattern(pattern uint64, patternBits int) (bitsSkipped int64, err error) {
+	if patternBits < 1 || patternBits > 64 {
+		return 0, n.sizeError("FindPattern", patternBits, 64)
+	}
+	mask := ^uint64(0) >> uint(64-patternBits)
+	pattern &= mask
+	var window uint64
+	for read := int64(1); ; read++ {
+		bit, err := n.nibble(1)
+		if err != nil {
+			return 0, err
+		}
+		window = (window<<1 | bit) & mask
+		if read < int64(patternBits) {
+			continue
+		}
+		skipped := read - int64(patternBits)
+		if window == pattern {
+			return skipped, nil
+		}
+		if n.scanLimit > 0 && skipped >= n.scanLimit {
+			return 0, n.wrap(fmt.Errorf("%w: pattern %#x not found in %d bits", ErrScanLimit, pattern, read))
+		}
+	}
+}
+
+// SkipToMarker aligns to the next byte boundary, as `Align` does, then skips
+// whole bytes until the following bytes equal `marker`, such as a JPEG or
+// MPEG start code, and returns the number of bits skipped, including those
+// skipped to align. The marker is not consumed, so the next read starts with
+// it.
+//
+// `marker` must not be empty and must fit in the read buffer, as for
+// `Measure`, otherwise nibs.ErrNibbleSize or an error wrapping ErrRewindLimit
+// is returned. io.EOF is returned if the stream ends without a match, and an
+// error wrapping ErrScanLimit if more bits than allowed by `WithScanLimit`
+// are skipped. Bits read while scanning are consumed in either case.
+func (n *Nibs) SkipToMarker(marker []byte) (bitsSkipped int64, err error) {
+	if len(marker) == 0 {
+		return 0, n.misuseError("SkipToMarker", "an empty marker")
+	}
+	aligned, err := n.Align()
+	if err != nil {
+		return 0, err
+	}
+	skipped := int64(aligned)
+	for {
+		first, err := n.Peek(8)
+		if err != nil {
+			return 0, err
+		}
+		if byte(first) == marker[0] {
+			match := true
+			_, err := n.Measure(func(n *Nibs) error {
+				for _, b := range marker {
+					v, err := n.nibble(8)
+					if err != nil {
+						return err
+					}
+					if byte(v) != b {
+						match = false
+						return nil
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return 0, err
+			}
+			if match {
+				return skipped, nil
+			}
+		}
+		if n.scanLimit > 0 && skipped+8 > n.scanLimit {
+			return 0, n.wrap(fmt.Errorf("%w: marker %#x not found in %d bits", ErrScanLimit, marker, skipped))
+		}
+		if _, err := n.nibble(8); err != nil {
+			return 0, err
+		}
+		skipped += 8
+	}
+}
@@ -0,0 +1,110 @@
+package nibs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+// syncStream returns `lead` zero bits, the 12-bit sync word 0xA5C and the
+// byte 0x3C.
+func syncStream(lead int) []byte {
+	ba := &BitArray{}
+	for i := 0; i < lead; i++ {
+		ba.Add(false)
+	}
+	ba.AddVar(0xA5C<<52, 12)
+	ba.Add8(0x3C)
+	return ba.Bytes()
+}
+
+func TestFindPattern(t *testing.T) {
+	for _, lead := range []int{0, 5, 37, 1000} {
+		nib := nibs.New(bytes.NewReader(syncStream(lead)))
+		skipped, err := nib.FindPattern(0xA5C, 12)
+		if err != nil || skipped != int64(lead) {
+			t.Errorf("expected %d bits skipped, got %d and error `%v`", lead, skipped, err)
+			continue
+		}
+		if v, err := nib.Nibble(8); err != nil || v != 0x3C {
+			t.Errorf("expected 0x3c after the pattern, got %#x and error `%v`", v, err)
+		}
+	}
+
+	// a match overlapping a partial match: 1011 within 101011
+	nib := nibs.New(bytes.NewReader(PackBits("1010 1100")))
+	if skipped, err := nib.FindPattern(0xB, 4); err != nil || skipped != 2 {
+		t.Errorf("expected 2 bits skipped, got %d and error `%v`", skipped, err)
+	}
+
+	// not found
+	nib = nibs.New(bytes.NewReader(make([]byte, 100)))
+	if _, err := nib.FindPattern(0xA5C, 12); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+
+	if _, err := nib.FindPattern(0, 0); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
+
+func TestWithScanLimit(t *testing.T) {
+	b := syncStream(37)
+
+	nib := nibs.New(bytes.NewReader(b), nibs.WithScanLimit(36))
+	if _, err := nib.FindPattern(0xA5C, 12); !errors.Is(err, nibs.ErrScanLimit) {
+		t.Errorf("expected `nibs.ErrScanLimit`, got `%v`", err)
+	}
+
+	nib = nibs.New(bytes.NewReader(b), nibs.WithScanLimit(37))
+	if skipped, err := nib.FindPattern(0xA5C, 12); err != nil || skipped != 37 {
+		t.Errorf("expected 37 bits skipped, got %d and error `%v`", skipped, err)
+	}
+
+	// no limit
+	nib = nibs.New(bytes.NewReader(syncStream(9<<20)), nibs.WithScanLimit(0))
+	if skipped, err := nib.FindPattern(0xA5C, 12); err != nil || skipped != 9<<20 {
+		t.Errorf("expected %d bits skipped, got %d and error `%v`", 9<<20, skipped, err)
+	}
+}
+
+func TestSkipToMarker(t *testing.T) {
+	marker := []byte{0xFF, 0xD8, 0xFF}
+	// a partial match before the marker
+	data := []byte{0x12, 0xFF, 0xD8, 0x00, 0x34, 0xFF, 0xD8, 0xFF, 0xE0}
+
+	for _, size := range []int{4, 64} {
+		nib := nibs.New(bytes.NewReader(data), nibs.WithBufferSize(size))
+		if _, err := nib.Nibble(3); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		skipped, err := nib.SkipToMarker(marker)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if skipped != 37 {
+			t.Errorf("expected 37 bits skipped, got %d", skipped)
+		}
+		if v, err := nib.Nibble(32); err != nil || v != 0xFFD8FFE0 {
+			t.Errorf("expected the marker next, got %#x and error `%v`", v, err)
+		}
+	}
+
+	nib := nibs.New(bytes.NewReader([]byte{0x00, 0xFF, 0xD8}))
+	if _, err := nib.SkipToMarker(marker); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+	if _, err := nib.SkipToMarker(nil); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+
+	nib = nibs.New(bytes.NewReader(data), nibs.WithScanLimit(16))
+	if _, err := nib.SkipToMarker(marker); !errors.Is(err, nibs.ErrScanLimit) {
+		t.Errorf("expected `nibs.ErrScanLimit`, got `%v`", err)
+	}
+}
@@ -0,0 +1,84 @@
+package nibs
+
+// ErrSignMode is the error used when an unknown SignMode is passed to a read method.
+var ErrSignMode = newSentinel("invalid sign mode")
+
+// SignMode is the representation used for signed integers.
+type SignMode int
+
+const (
+	// TwosComplement is the common two's complement representation.
+	TwosComplement SignMode = iota
+	// OnesComplement represents negative values by inverting all bits of the
+	// magnitude. All ones is negative zero.
+	OnesComplement
+	// SignMagnitude represents values with the left-most bit as the sign and
+	// the remaining bits as the magnitude. A sign bit alone is negative zero.
+	SignMagnitude
+)
+
+// NibbleSignedMode reads `bits` number of bits from the byte stream and
+// returns the value as an int64, interpreting the left-most bit as the sign
+// according to `mode`. Negative zero is returned as 0.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned. ErrSignMode is returned for an unknown `mode`.
+func (n *Nibs) NibbleSignedMode(bits int, mode SignMode) (int64, error) {
+	if bits < 1 || bits > 64 {
+		return 0, n.sizeError("NibbleSignedMode", bits, 64)
+	}
+	if mode < TwosComplement || mode > SignMagnitude {
+		return 0, n.wrap(ErrSignMode)
+	}
+
+	v, err := n.Nibble(bits)
+	if err != nil {
+		return 0, err
+	}
+
+	mask := ^uint64(0) >> uint(64-bits)
+	negative := v>>uint(bits-1) == 1
+
+	switch mode {
+	case OnesComplement:
+		if negative {
+			return -int64(^v & mask), nil
+		}
+		return int64(v), nil
+	case SignMagnitude:
+		mag := int64(v & (mask >> 1))
+		if negative {
+			return -mag, nil
+		}
+		return mag, nil
+	default:
+		return signExtend(v, bits), nil
+	}
+}
+
+// signExtend returns the two's complement value of the low `bits` bits of `v`.
+func signExtend(v uint64, bits int) int64 {
+	shift := uint(64 - bits)
+	return int64(v<<shift) >> shift
+}
+
+// NibbleSignedSlice fills `dst` with two's complement values of `bitsEach`
+// bits each, sign extended, as with arrays of signed PCM samples, and returns
+// the number of values read. Several values are read at a time where they fit
+// in 64 bits.
+//
+// `bitsEach` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned. If fewer than len(`dst`) values remain, the
+// values read are returned along with io.EOF if none were read, or
+// io.ErrUnexpectedEOF otherwise.
+func (n *Nibs) NibbleSignedSlice(dst []int64, bitsEach int) (int, error) {
+	if bitsEach < 1 || bitsEach > 64 {
+		return 0, n.sizeError("NibbleSignedSlice", bitsEach, 64)
+	}
+
+	c, err := n.readPacked(len(dst), bitsEach, func(i int, v uint64) { dst[i] = signExtend(v, bitsEach) })
+	if err != nil && c > 0 {
+		err = unexpected(err)
+	}
+	return c, err
+}
@@ -0,0 +1,106 @@
+package nibs_test
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+	. "github.com/wiggin77/nibs/_test"
+)
+
+func TestNibbleSignedMode(t *testing.T) {
+	tests := []struct {
+		data     []byte
+		bits     int
+		mode     nibs.SignMode
+		expected int64
+	}{
+		{data: []byte{0xFD}, bits: 8, mode: nibs.TwosComplement, expected: -3},
+		{data: []byte{0xFC}, bits: 8, mode: nibs.OnesComplement, expected: -3},
+		{data: []byte{0x83}, bits: 8, mode: nibs.SignMagnitude, expected: -3},
+		{data: []byte{0xF0}, bits: 4, mode: nibs.TwosComplement, expected: -1},
+		{data: []byte{0xE0}, bits: 4, mode: nibs.OnesComplement, expected: -1},
+		{data: []byte{0x90}, bits: 4, mode: nibs.SignMagnitude, expected: -1},
+		{data: []byte{0x70}, bits: 4, mode: nibs.TwosComplement, expected: 7},
+		{data: []byte{0x70}, bits: 4, mode: nibs.OnesComplement, expected: 7},
+		{data: []byte{0x70}, bits: 4, mode: nibs.SignMagnitude, expected: 7},
+		{data: []byte{0xFF}, bits: 8, mode: nibs.OnesComplement, expected: 0},
+		{data: []byte{0x80}, bits: 8, mode: nibs.SignMagnitude, expected: 0},
+		{data: []byte{0x80}, bits: 1, mode: nibs.TwosComplement, expected: -1},
+		{data: []byte{0x80, 0, 0, 0, 0, 0, 0, 0}, bits: 64, mode: nibs.TwosComplement, expected: math.MinInt64},
+		{data: []byte{0x80, 0, 0, 0, 0, 0, 0, 0}, bits: 64, mode: nibs.OnesComplement, expected: -math.MaxInt64},
+		{data: []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, bits: 64, mode: nibs.SignMagnitude, expected: -math.MaxInt64},
+	}
+
+	for _, tt := range tests {
+		nib := nibs.New(bytes.NewReader(tt.data))
+		v, err := nib.NibbleSignedMode(tt.bits, tt.mode)
+		if err != nil || v != tt.expected {
+			t.Errorf("expected %d for %x (%d bits, mode %d), got %d and error `%v`",
+				tt.expected, tt.data, tt.bits, tt.mode, v, err)
+		}
+	}
+}
+
+func TestNibbleSignedModeErrors(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xFF}))
+
+	if _, err := nib.NibbleSignedMode(0, nibs.TwosComplement); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, err := nib.NibbleSignedMode(8, nibs.SignMode(3)); err != nibs.ErrSignMode {
+		t.Errorf("expected `nibs.ErrSignMode`, got `%v`", err)
+	}
+}
+
+func TestNibbleSignedSlice(t *testing.T) {
+	const count = 256
+	expected := make([]int64, count)
+	for i := range expected {
+		// mixed sign values across the 12-bit range
+		expected[i] = int64(i*37%4096) - 2048
+	}
+	nib := signedStream(12, expected...)
+
+	dst := make([]int64, count)
+	c, err := nib.NibbleSignedSlice(dst, 12)
+	if err != nil || c != count {
+		t.Fatalf("expected %d values, got %d and error `%v`", count, c, err)
+	}
+	for i := range dst {
+		if dst[i] != expected[i] {
+			t.Errorf("value %d: expected %d, got %d", i, expected[i], dst[i])
+		}
+	}
+	if c, err := nib.NibbleSignedSlice(dst, 12); err != io.EOF || c != 0 {
+		t.Errorf("expected 0 values and `io.EOF`, got %d and `%v`", c, err)
+	}
+}
+
+func TestNibbleSignedSliceWidths(t *testing.T) {
+	// 64-bit values, one per read
+	b := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFE, 0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+	nib := nibs.New(bytes.NewReader(b))
+	dst := make([]int64, 3)
+	c, err := nib.NibbleSignedSlice(dst, 64)
+	if err != io.ErrUnexpectedEOF || c != 2 || dst[0] != -2 || dst[1] != math.MaxInt64 {
+		t.Errorf("expected [-2 MaxInt64] and `io.ErrUnexpectedEOF`, got %v, %d and `%v`", dst[:c], c, err)
+	}
+
+	// 3-bit values with a partial read at the end of the stream
+	nib = nibs.New(bytes.NewReader(PackBits("011 100 111 000 1111 0000")))
+	dst = make([]int64, 10)
+	c, err = nib.NibbleSignedSlice(dst, 3)
+	if err != io.ErrUnexpectedEOF || c != 8 {
+		t.Fatalf("expected 8 values and `io.ErrUnexpectedEOF`, got %d and `%v`", c, err)
+	}
+	if got := dst[:4]; got[0] != 3 || got[1] != -4 || got[2] != -1 || got[3] != 0 {
+		t.Errorf("expected [3 -4 -1 0], got %v", got)
+	}
+
+	if _, err := nib.NibbleSignedSlice(dst, 0); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
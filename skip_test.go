@@ -0,0 +1,191 @@
+package nibs_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+)
+
+// readerOnly hides any io.Seeker implemented by the wrapped reader, forcing
+// `Skip` to read and discard.
+type readerOnly struct {
+	io.Reader
+}
+
+func TestSkip(t *testing.T) {
+	const size = 4096
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	for _, lead := range []int{0, 3, 13} {
+		for _, bits := range []int64{0, 1, 7, 64, 513, 2000*8 + 5, 3500 * 8} {
+			seeked := nibs.New(bytes.NewReader(b))
+			discarded := nibs.New(readerOnly{bytes.NewReader(b)})
+
+			for _, nib := range []*nibs.Nibs{seeked, discarded} {
+				if _, err := nib.Nibble(lead + 1); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if err := nib.Skip(bits); err != nil {
+					t.Fatalf("unexpected error skipping %d bits: %v", bits, err)
+				}
+			}
+
+			expectedRead := int64(lead+1) + bits
+			if seeked.BitsRead() != expectedRead || discarded.BitsRead() != expectedRead {
+				t.Errorf("expected %d bits read, got %d seeking and %d discarding",
+					expectedRead, seeked.BitsRead(), discarded.BitsRead())
+			}
+			for i := 0; i < 8; i++ {
+				v1, err1 := seeked.Nibble(11)
+				v2, err2 := discarded.Nibble(11)
+				if v1 != v2 || err1 != err2 {
+					t.Fatalf("skip %d after %d bits: seeking read %d (%v), discarding read %d (%v)",
+						bits, lead+1, v1, err1, v2, err2)
+				}
+			}
+		}
+	}
+}
+
+func TestSkipEOF(t *testing.T) {
+	b := bytes.Repeat([]byte{0xA5}, 1024)
+	nib := nibs.New(bytes.NewReader(b))
+	if _, err := nib.Nibble(4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := nib.Skip(1024 * 8); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+	// no bits consumed
+	if v, err := nib.Nibble(8); err != nil || v != 0x5A {
+		t.Errorf("expected 0x5A, got %#x and error `%v`", v, err)
+	}
+
+	// skip exactly to the end
+	if err := nib.Skip(1024*8 - 12); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := nib.Nibble(1); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+
+	if err := nib.Skip(-1); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
+
+func TestSkipSplitBody(t *testing.T) {
+	b := bytes.Repeat([]byte{0x0F}, 1024)
+	nib := nibs.New(bytes.NewReader(b))
+	_, body, err := nib.SplitAt(12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := body.Skip(1024 * 8); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+	if err := body.Skip(1000 * 8); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if v, err := body.Nibble(8); err != nil || v != 0xF0 {
+		t.Errorf("expected 0xF0, got %#x and error `%v`", v, err)
+	}
+}
+
+func benchmarkSkip(b *testing.B, seek bool) {
+	data := make([]byte, 100<<20)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var r io.Reader = bytes.NewReader(data)
+		if !seek {
+			r = readerOnly{r}
+		}
+		nib := nibs.New(r)
+		if err := nib.Skip(int64(len(data))*8 - 8); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := nib.Nibble(8); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func BenchmarkSkipSeek(b *testing.B) {
+	benchmarkSkip(b, true)
+}
+
+func BenchmarkSkipDiscard(b *testing.B) {
+	benchmarkSkip(b, false)
+}
+
+func TestRewind(t *testing.T) {
+	b := make([]byte, 1000)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	nib := nibs.New(bytes.NewReader(b), nibs.WithValueTrace())
+
+	// partial read, then a full read to EOF
+	for i := 0; i < 2; i++ {
+		if _, err := nib.Nibble(13); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		nib.Enter("field")
+		if err := nib.Rewind(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if nib.BitsRead() != 0 || nib.Path() != "" || nib.Trace() != nil {
+			t.Errorf("expected state reset, got %d bits read, path `%s`, trace %v",
+				nib.BitsRead(), nib.Path(), nib.Trace())
+		}
+
+		out := make([]byte, len(b))
+		for j := range out {
+			v, err := nib.Nibble(8)
+			if err != nil {
+				t.Fatalf("unexpected error at byte %d: %v", j, err)
+			}
+			out[j] = byte(v)
+		}
+		if !bytes.Equal(b, out) {
+			t.Error("re-read differs from original")
+		}
+		if _, err := nib.Nibble(1); err != io.EOF {
+			t.Errorf("expected `io.EOF`, got `%v`", err)
+		}
+		if err := nib.Rewind(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestRewindSplitBody(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xAB, 0xCD, 0xEF}))
+	_, body, err := nib.SplitAt(4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if v, err := body.Nibble(20); err != nil || v != 0xBCDEF {
+			t.Errorf("expected 0xBCDEF, got %#x and error `%v`", v, err)
+		}
+		if err := body.Rewind(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestRewindNotSeekable(t *testing.T) {
+	nib := nibs.New(readerOnly{bytes.NewReader([]byte{0xAB})})
+	if err := nib.Rewind(); err != nibs.ErrNotSeekable {
+		t.Errorf("expected `nibs.ErrNotSeekable`, got `%v`", err)
+	}
+}
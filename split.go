@@ -0,0 +1,76 @@
+package nibs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ErrNotSeekable is the error used when an operation requires random access
+// to the underlying reader and the reader does not provide it.
+var ErrNotSeekable = newSentinel("reader is not seekable")
+
+// SplitAt returns two new independent Nibs reading from the same source: `header`
+// reads the first `bitOffset` bits of the stream and then reports io.EOF, and
+// `body` reads the remainder of the stream starting at `bitOffset`. The bit
+// positions of `body` are relative to its start.
+//
+// The reader passed to `New` must implement io.ReaderAt, as *bytes.Reader,
+// *strings.Reader, *io.SectionReader and *os.File do, otherwise ErrNotSeekable
+// is returned. Offsets are measured from offset 0 of the io.ReaderAt, which
+// must be the start of the stream, or from the start of the body for a body
+// returned by an earlier split. A negative `bitOffset`, or one past the end
+// of a header, returns nibs.ErrNibbleSize. Neither `n` nor its position is
+// affected.
+// Both are configured with the options passed to `New` for `n`.
+func (n *Nibs) SplitAt(bitOffset int64) (header *Nibs, body *Nibs, err error) {
+	ra, ok := n.reader.(io.ReaderAt)
+	if !ok {
+		return nil, nil, n.wrap(ErrNotSeekable)
+	}
+	if bitOffset < 0 || n.limit >= 0 && bitOffset > n.limit {
+		return nil, nil, n.misuseError("SplitAt", fmt.Sprintf("an offset of %d bits; must be 0 to the length of the stream", bitOffset))
+	}
+	// offsets within the reader, which holds the bits skipped by an earlier
+	// split ahead of the stream
+	lead := int64(n.lead)
+	end := lead + bitOffset
+
+	header, err = n.section(ra, 0, lead, (end+7)/8)
+	if err != nil {
+		return nil, nil, err
+	}
+	header.limit = bitOffset
+
+	// bound the body by the size of the source when known, so seeking past
+	// the end of the body is reported as io.EOF
+	start := end / 8
+	size := int64(math.MaxInt64)
+	if sz, ok := ra.(interface{ Size() int64 }); ok && sz.Size() >= start {
+		size = sz.Size()
+	}
+	body, err = n.section(ra, start, end%8, size-start)
+	if err != nil {
+		return nil, nil, err
+	}
+	if n.limit >= 0 {
+		body.limit = n.limit - bitOffset
+	}
+	return header, body, nil
+}
+
+// section returns a new Nibs, configured like `n`, reading the `size` bytes of
+// `ra` at byte offset `off` from bit `lead` of them onwards, with its position
+// at zero.
+func (n *Nibs) section(ra io.ReaderAt, off, lead, size int64) (*Nibs, error) {
+	s := New(io.NewSectionReader(ra, off, size), n.opts...)
+	if lead > 0 {
+		s.lead = int(lead)
+		s.base = -lead
+		if err := s.Skip(lead); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+	}
+	return s, nil
+}
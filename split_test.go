@@ -0,0 +1,137 @@
+package nibs_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestSplitAt(t *testing.T) {
+	// 12-bit header followed by a 20-bit body
+	b := PackBits("101011001111 00010010001101000101")
+	nib := nibs.New(bytes.NewReader(b))
+
+	header, body, err := nib.SplitAt(12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if v, err := header.Nibble(4); err != nil || v != 0xA {
+		t.Errorf("expected 0xa, got %#x and error `%v`", v, err)
+	}
+	if r, err := header.BitsRemaining(); err != nil || r != 8 {
+		t.Errorf("expected 8 header bits remaining, got %d and error `%v`", r, err)
+	}
+	if v, err := header.Nibble(8); err != nil || v != 0xCF {
+		t.Errorf("expected 0xcf, got %#x and error `%v`", v, err)
+	}
+	if _, err := header.Nibble(1); err != io.EOF {
+		t.Errorf("expected `io.EOF` after header, got `%v`", err)
+	}
+
+	if v, err := body.Nibble(20); err != nil || v != 0x12345 {
+		t.Errorf("expected body 0x12345, got %#x and error `%v`", v, err)
+	}
+	if _, err := body.Nibble(1); err != io.EOF {
+		t.Errorf("expected `io.EOF` after body, got `%v`", err)
+	}
+
+	// the original is unaffected
+	if v, err := nib.Nibble(32); err != nil || v != 0xACF12345 {
+		t.Errorf("expected 0xacf12345, got %#x and error `%v`", v, err)
+	}
+}
+
+func TestSplitAtNested(t *testing.T) {
+	// split at 4 bits, then the body again at 8 bits
+	nib := nibs.New(bytes.NewReader([]byte{0x00, 0xFF, 0x00, 0xAA}))
+	_, body, err := nib.SplitAt(4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	header2, body2, err := body.SplitAt(8)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if v, err := header2.Nibble(8); err != nil || v != 0x0F {
+		t.Errorf("expected 0x0f, got %#x and error `%v`", v, err)
+	}
+	if _, err := header2.Nibble(1); err != io.EOF {
+		t.Errorf("expected `io.EOF` after header, got `%v`", err)
+	}
+	if v, err := body2.Nibble(20); err != nil || v != 0xF00AA {
+		t.Errorf("expected 0xf00aa, got %#x and error `%v`", v, err)
+	}
+	if _, err := body2.Nibble(1); err != io.EOF {
+		t.Errorf("expected `io.EOF` after body, got `%v`", err)
+	}
+
+	// a split at a whole byte of an unaligned body keeps its lead
+	header3, body3, err := body.SplitAt(12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, err := header3.Nibble(12); err != nil || v != 0x0FF {
+		t.Errorf("expected 0x0ff, got %#x and error `%v`", v, err)
+	}
+	if v, err := body3.Nibble(16); err != nil || v != 0x00AA {
+		t.Errorf("expected 0x00aa, got %#x and error `%v`", v, err)
+	}
+}
+
+func TestSplitAtLarge(t *testing.T) {
+	const size = 1000
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	nib := nibs.New(bytes.NewReader(b))
+
+	// split at byte 500 plus 4 bits
+	header, body, err := nib.SplitAt(500*8 + 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 500; i++ {
+		if v, err := header.Nibble(8); err != nil || v != uint64(byte(i)) {
+			t.Fatalf("expected header byte %d, got %d and error `%v`", byte(i), v, err)
+		}
+	}
+	if v, err := header.Nibble(4); err != nil || v != uint64(b[500]>>4) {
+		t.Errorf("expected last header nibble %d, got %d and error `%v`", b[500]>>4, v, err)
+	}
+	if _, err := header.Nibble(1); err != io.EOF {
+		t.Errorf("expected `io.EOF` after header, got `%v`", err)
+	}
+
+	if v, err := body.Nibble(4); err != nil || v != uint64(b[500]&0x0F) {
+		t.Errorf("expected first body nibble %d, got %d and error `%v`", b[500]&0x0F, v, err)
+	}
+	for i := 501; i < size; i++ {
+		if v, err := body.Nibble(8); err != nil || v != uint64(byte(i)) {
+			t.Fatalf("expected body byte %d, got %d and error `%v`", byte(i), v, err)
+		}
+	}
+	if _, err := body.Nibble(1); err != io.EOF {
+		t.Errorf("expected `io.EOF` after body, got `%v`", err)
+	}
+}
+
+func TestSplitAtNotSeekable(t *testing.T) {
+	nib := nibs.NewHex(strings.NewReader("deadbeef"))
+	if _, _, err := nib.SplitAt(8); err != nibs.ErrNotSeekable {
+		t.Errorf("expected `nibs.ErrNotSeekable`, got `%v`", err)
+	}
+
+	nib = nibs.New(NewFlakyReader(bytes.NewReader(make([]byte, 8)), 8))
+	if _, _, err := nib.SplitAt(8); err != nibs.ErrNotSeekable {
+		t.Errorf("expected `nibs.ErrNotSeekable`, got `%v`", err)
+	}
+}
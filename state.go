@@ -0,0 +1,49 @@
+package nibs
+
+// state is the state affected by reads, apart from the position within the
+// stream, as saved by `Measure` and at the start of a field for `RetryField`.
+type state struct {
+	start          int64 // absolute bit offset that BitsRead counts from
+	path           int   // number of fields entered
+	level          bool
+	trace          int
+	fletcherA      uint16
+	fletcherB      uint16
+	fletcherSummed int64 // absolute bit offset summed to
+	monotonic      uint64
+	monotonicSeen  bool
+	delta          int64
+	xorPrev        uint64
+	dpcm           []int64 // copy of the DPCM history
+}
+
+// save records the state of `n`, reusing the memory of any state saved before.
+func (s *state) save(n *Nibs) {
+	if n.fletcher {
+		n.sumFletcher()
+	}
+	s.start = n.start
+	s.path = len(n.path)
+	s.level = n.level
+	s.trace = len(n.trace)
+	s.fletcherA, s.fletcherB = n.fletcherA, n.fletcherB
+	s.fletcherSummed = n.base + int64(n.fletcherSummed)*8
+	s.monotonic, s.monotonicSeen = n.monotonic, n.monotonicSeen
+	s.delta, s.xorPrev = n.delta, n.xorPrev
+	s.dpcm = append(s.dpcm[:0], n.dpcm...)
+}
+
+// restore returns `n` to the state saved. The zero state is that of a new Nibs.
+func (s *state) restore(n *Nibs) {
+	n.start = s.start
+	if len(n.path) > s.path {
+		n.path = n.path[:s.path]
+	}
+	n.level = s.level
+	n.trace = n.trace[:s.trace]
+	n.fletcherA, n.fletcherB = s.fletcherA, s.fletcherB
+	n.fletcherSummed = int((s.fletcherSummed - n.base) / 8)
+	n.monotonic, n.monotonicSeen = s.monotonic, s.monotonicSeen
+	n.delta, n.xorPrev = s.delta, s.xorPrev
+	n.dpcm = append(n.dpcm[:0], s.dpcm...)
+}
@@ -0,0 +1,31 @@
+package nibs
+
+// WithReadStats returns an option that counts calls made to the underlying
+// reader, for diagnosing slow readers. See `ReadStats`.
+func WithReadStats() Option {
+	return func(n *Nibs) {
+		n.stats = true
+	}
+}
+
+// ReadStats returns the number of `Read` calls made to the underlying reader,
+// how many of those were second reads forced after a short read returned no
+// error, and the number of times the buffer was refilled. Many forced reads
+// mean the reader returns less than asked, such as a network connection or an
+// unbuffered file, and wrapping it in a bufio.Reader may help.
+//
+// Zero is returned for all counts unless the option `WithReadStats` is used.
+func (n *Nibs) ReadStats() (reads, forcedReads, refills int) {
+	return n.reads, n.forcedReads, n.refills
+}
+
+// WithShortReadHook returns an option that calls `fn` whenever the underlying
+// reader returns fewer bytes than requested and no error, with the number of
+// bytes returned and requested. A reader that does so often may benefit from
+// being wrapped in a bufio.Reader. `fn` is only called when the buffer is
+// refilled, so it costs nothing on reads served from the buffer.
+func WithShortReadHook(fn func(got, want int)) Option {
+	return func(n *Nibs) {
+		n.shortReadHook = fn
+	}
+}
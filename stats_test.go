@@ -0,0 +1,77 @@
+package nibs_test
+
+import (
+	"bytes"
+	"testing"
+	"testing/iotest"
+
+	"github.com/wiggin77/nibs"
+)
+
+// readBytes reads `count` bytes from `nib`.
+func readBytes(t *testing.T, nib *nibs.Nibs, count int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		if _, err := nib.Nibble(8); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestReadStats(t *testing.T) {
+	const size = 1000
+	b := make([]byte, size)
+
+	// a reader returning one byte at a time forces a second read on every refill
+	nib := nibs.New(iotest.OneByteReader(bytes.NewReader(b)), nibs.WithReadStats())
+	readBytes(t, nib, size)
+	reads, forced, refills := nib.ReadStats()
+	if refills < size/2 || forced != refills || reads != refills+forced {
+		t.Errorf("expected many forced reads, got reads=%d forced=%d refills=%d", reads, forced, refills)
+	}
+
+	// a bytes.Reader fills the buffer on each read
+	nib = nibs.New(bytes.NewReader(b), nibs.WithReadStats())
+	readBytes(t, nib, size)
+	reads, forced, refills = nib.ReadStats()
+	if refills != size/64+1 || forced != 1 || reads != refills+forced {
+		t.Errorf("expected few reads, got reads=%d forced=%d refills=%d", reads, forced, refills)
+	}
+
+	// without the option
+	nib = nibs.New(bytes.NewReader(b))
+	readBytes(t, nib, size)
+	if reads, forced, refills = nib.ReadStats(); reads != 0 || forced != 0 || refills != 0 {
+		t.Errorf("expected zero counts without option, got reads=%d forced=%d refills=%d", reads, forced, refills)
+	}
+}
+
+func TestWithShortReadHook(t *testing.T) {
+	type call struct{ got, want int }
+	var calls []call
+	hook := func(got, want int) {
+		calls = append(calls, call{got, want})
+	}
+
+	// each refill reads one byte of the 64 requested, then is forced to read
+	// again looking for EOF and gets one of the 63 left
+	nib := nibs.New(iotest.OneByteReader(bytes.NewReader(make([]byte, 4))), nibs.WithShortReadHook(hook))
+	readBytes(t, nib, 4)
+	expected := []call{{1, 64}, {1, 63}, {1, 64}, {1, 63}}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected %d calls, got %v", len(expected), calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("call %d: expected %v, got %v", i, expected[i], calls[i])
+		}
+	}
+
+	// a reader filling the buffer until EOF
+	calls = nil
+	nib = nibs.New(bytes.NewReader(make([]byte, 128)), nibs.WithShortReadHook(hook))
+	readBytes(t, nib, 128)
+	if len(calls) != 0 {
+		t.Errorf("expected no calls, got %v", calls)
+	}
+}
@@ -0,0 +1,93 @@
+package nibs
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// Stream reads values of `bits` bits each and sends them on `ch` until the
+// end of the stream, for pipelines where another goroutine consumes the values
+// as they are decoded. `ch` is closed when Stream returns.
+//
+// nil is returned at the end of the stream. Any trailing bits too few to make
+// a full value are left unread (see `BitsRemaining`). Any other error from
+// reading is returned, as is ctx.Err() if `ctx` is done before the stream
+// ends, including while waiting to send.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+func (n *Nibs) Stream(ctx context.Context, bits int, ch chan<- uint64) error {
+	defer close(ch)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		v, err := n.Nibble(bits)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		select {
+		case ch <- v:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// NibbleAllCtx reads values of `bits` bits each and calls `fn` with each one
+// until the end of the stream, `fn` returns an error, or `ctx` is done. `ctx`
+// is checked whenever the read buffer needs refilling, before reading from
+// the underlying reader, so cancellation is noticed promptly without a check
+// on every value.
+//
+// nil is returned at the end of the stream. Any trailing bits too few to make
+// a full value are left unread (see `BitsRemaining`). Otherwise the error from
+// `fn`, ctx.Err(), or any other error from reading is returned.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+func (n *Nibs) NibbleAllCtx(ctx context.Context, bits int, fn func(uint64) error) error {
+	for {
+		if n.remaining() < bits {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		v, err := n.Nibble(bits)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+}
+
+// StreamTyped starts a goroutine that reads values of `bits` bits each, as
+// `Stream` does, and returns the channel they are sent on and a channel for
+// the error that stopped it. The value channel is buffered for 64 values, so
+// a slow consumer holds back the decoding, and is closed when the goroutine
+// stops. The error channel is then sent the error, if any, as `Stream` would
+// return it, and closed, so receiving from it after draining the values gives
+// nil at the end of the stream.
+//
+// The Nibs must not be used by the caller until the error channel is closed.
+// Cancelling `ctx` stops the goroutine even if the values are not drained.
+func (n *Nibs) StreamTyped(ctx context.Context, bits int) (<-chan uint64, <-chan error) {
+	vals := make(chan uint64, 64)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		if err := n.Stream(ctx, bits, vals); err != nil {
+			errs <- err
+		}
+	}()
+	return vals, errs
+}
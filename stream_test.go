@@ -0,0 +1,187 @@
+package nibs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestStream(t *testing.T) {
+	const count = 10000
+	b := make([]byte, count*2+1)
+	for i := 0; i < count; i++ {
+		b[i*2] = byte(i >> 8)
+		b[i*2+1] = byte(i)
+	}
+	nib := nibs.New(bytes.NewReader(b))
+
+	ch := make(chan uint64, 16)
+	done := make(chan []uint64)
+	go func() {
+		var vals []uint64
+		for v := range ch {
+			vals = append(vals, v)
+		}
+		done <- vals
+	}()
+
+	if err := nib.Stream(context.Background(), 16, ch); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	vals := <-done
+	if len(vals) != count {
+		t.Fatalf("expected %d values, got %d", count, len(vals))
+	}
+	for i, v := range vals {
+		if v != uint64(i&0xFFFF) {
+			t.Fatalf("expected %d at %d, got %d", i, i, v)
+		}
+	}
+	if left, err := nib.BitsRemaining(); err != nil || left != 8 {
+		t.Errorf("expected 8 bits remaining, got %d and error `%v`", left, err)
+	}
+}
+
+func TestStreamCancel(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(make([]byte, 1024)))
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// unbuffered channel with a consumer that stops after 10 values
+	ch := make(chan uint64)
+	go func() {
+		for i := 0; i < 10; i++ {
+			<-ch
+		}
+		cancel()
+	}()
+
+	if err := nib.Stream(ctx, 8, ch); err != context.Canceled {
+		t.Errorf("expected `context.Canceled`, got `%v`", err)
+	}
+	if _, ok := <-ch; ok {
+		t.Error("expected channel to be closed")
+	}
+	if read := nib.BitsRead(); read < 80 || read > 88 {
+		t.Errorf("expected about 80 bits read, got %d", read)
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xAB}))
+	ch := make(chan uint64, 1)
+	if err := nib.Stream(context.Background(), 65, ch); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, ok := <-ch; ok {
+		t.Error("expected channel to be closed")
+	}
+}
+
+func TestNibbleAllCtx(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(make([]byte, 1001)))
+	var count int
+	err := nib.NibbleAllCtx(context.Background(), 16, func(uint64) error {
+		count++
+		return nil
+	})
+	if err != nil || count != 500 {
+		t.Errorf("expected 500 values, got %d and error `%v`", count, err)
+	}
+	if left, err := nib.BitsRemaining(); err != nil || left != 8 {
+		t.Errorf("expected 8 bits remaining, got %d and error `%v`", left, err)
+	}
+
+	// an error from fn stops the loop
+	errStop := errors.New("stop")
+	nib = nibs.New(bytes.NewReader(make([]byte, 100)))
+	count = 0
+	err = nib.NibbleAllCtx(context.Background(), 8, func(uint64) error {
+		if count++; count == 3 {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop || nib.BitsRead() != 24 {
+		t.Errorf("expected `errStop` after 24 bits, got %d bits and error `%v`", nib.BitsRead(), err)
+	}
+}
+
+func TestNibbleAllCtxCancel(t *testing.T) {
+	nib := nibs.New(bytes.NewReader(make([]byte, 1<<20)))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// cancelled part way, the loop stops by the next refill of the 64 byte buffer
+	var count int
+	err := nib.NibbleAllCtx(ctx, 8, func(uint64) error {
+		if count++; count == 1000 {
+			cancel()
+		}
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("expected `context.Canceled`, got `%v`", err)
+	}
+	if count < 1000 || count > 1000+64 {
+		t.Errorf("expected about 1000 values, got %d", count)
+	}
+}
+
+func TestStreamTyped(t *testing.T) {
+	const count = 5000
+	b := make([]byte, count*2)
+	for i := 0; i < count; i++ {
+		b[i*2] = byte(i >> 8)
+		b[i*2+1] = byte(i)
+	}
+	nib := nibs.New(bytes.NewReader(b))
+
+	vals, errs := nib.StreamTyped(context.Background(), 16)
+	var i int
+	for v := range vals {
+		if v != uint64(i) {
+			t.Fatalf("expected %d at %d, got %d", i, i, v)
+		}
+		i++
+	}
+	if err := <-errs; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if i != count {
+		t.Errorf("expected %d values, got %d", count, i)
+	}
+	if _, ok := <-errs; ok {
+		t.Error("expected error channel to be closed")
+	}
+}
+
+func TestStreamTypedErrors(t *testing.T) {
+	// the reader fails after about 100 bytes
+	nib := nibs.New(NewFlakyReader(bytes.NewReader(make([]byte, 1000)), 100))
+	vals, errs := nib.StreamTyped(context.Background(), 8)
+	var count int
+	for range vals {
+		count++
+	}
+	if err := <-errs; !errors.Is(err, ErrFlaky) {
+		t.Errorf("expected `ErrFlaky`, got `%v`", err)
+	}
+	if count < 100 || count >= 1000 {
+		t.Errorf("expected about 100 values before the error, got %d", count)
+	}
+
+	// cancelled without draining the values
+	nib = nibs.New(bytes.NewReader(make([]byte, 1<<20)))
+	ctx, cancel := context.WithCancel(context.Background())
+	vals, errs = nib.StreamTyped(ctx, 8)
+	<-vals
+	cancel()
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("expected `context.Canceled`, got `%v`", err)
+	}
+}
@@ -0,0 +1,56 @@
+package nibs
+
+import "fmt"
+
+// DecodeTable is a precomputed lookup for reading a field of a fixed width of
+// 8 bits or fewer with `NibbleTable`. It holds the value of the field for
+// every byte and every bit position within the byte the field fits at, so a
+// buffered field is decoded with a single lookup.
+//
+// A DecodeTable is not modified once built and may be shared by any number
+// of Nibs, including concurrently.
+type DecodeTable struct {
+	bits int
+	vals [256 * 8]uint8 // indexed by byte<<3 | bit position
+}
+
+// NewDecodeTable returns a DecodeTable for fields of `bits` bits.
+//
+// NewDecodeTable panics if `bits` is not in the range 1 to 8 inclusive.
+func NewDecodeTable(bits int) *DecodeTable {
+	if bits < 1 || bits > 8 {
+		panic(fmt.Sprintf("nibs: NewDecodeTable called with %d bits; must be 1 to 8", bits))
+	}
+	t := &DecodeTable{bits: bits}
+	mask := uint8(0xFF) >> uint(8-bits)
+	for b := 0; b < 256; b++ {
+		for p := 0; p+bits <= 8; p++ {
+			t.vals[b<<3|p] = uint8(b) >> uint(8-p-bits) & mask
+		}
+	}
+	return t
+}
+
+// Bits returns the width of the fields decoded by the table.
+func (t *DecodeTable) Bits() int {
+	return t.bits
+}
+
+// NibbleTable reads a field of the width `t` was built for, and returns the
+// same value and errors as `Nibble`. When the field is buffered and lies
+// within a single byte, as every field does in a byte aligned run of fields
+// whose width divides 8, it is decoded by lookup; otherwise, such as when a
+// field straddles a byte boundary, NibbleTable falls back to `Nibble`.
+//
+// It is intended for hot loops reading many small fields, such as 4-bit
+// samples, where the per-call cost of `Nibble` dominates.
+func (n *Nibs) NibbleTable(t *DecodeTable) (uint64, error) {
+	p := n.pos % 8
+	if p+t.bits > 8 || n.remaining() < t.bits || n.tracing || n.bitChecksum != nil ||
+		n.autoAlign > 1 || (n.limit >= 0 && int64(t.bits) > n.limit-n.offset()) {
+		return n.Nibble(t.bits)
+	}
+	v := t.vals[int(n.buf[n.pos/8])<<3|p]
+	n.pos += t.bits
+	return uint64(v), nil
+}
@@ -0,0 +1,90 @@
+package nibs_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestNibbleTable(t *testing.T) {
+	data := make([]byte, 1000)
+	if _, err := rand.Read(data); err != nil {
+		panic(err)
+	}
+
+	for _, bits := range []int{1, 3, 4, 7, 8} {
+		table := nibs.NewDecodeTable(bits)
+		if table.Bits() != bits {
+			t.Errorf("expected %d bits, got %d", bits, table.Bits())
+		}
+		want := nibs.New(bytes.NewReader(data))
+		got := nibs.New(bytes.NewReader(data), nibs.WithBufferSize(16))
+		for i := 0; ; i++ {
+			expected, expectedErr := want.Nibble(bits)
+			v, err := got.NibbleTable(table)
+			if v != expected || err != expectedErr {
+				t.Fatalf("%d bits, field %d: expected (%d, %v), got (%d, %v)", bits, i, expected, expectedErr, v, err)
+			}
+			if err != nil {
+				break
+			}
+		}
+	}
+}
+
+func TestNibbleTableUnaligned(t *testing.T) {
+	// a 4-bit field after a 2-bit one straddles the byte
+	nib := nibs.New(bytes.NewReader([]byte{0xB4, 0x8F}))
+	table := nibs.NewDecodeTable(4)
+
+	if _, err := nib.Nibble(6); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []uint64{0x02, 0x03} {
+		v, err := nib.NibbleTable(table)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v != expected {
+			t.Errorf("expected %#x, got %#x", expected, v)
+		}
+	}
+	if _, err := nib.NibbleTable(table); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestNewDecodeTablePanics(t *testing.T) {
+	for _, bits := range []int{0, 9} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic for %d bits", bits)
+				}
+			}()
+			nibs.NewDecodeTable(bits)
+		}()
+	}
+}
+
+func BenchmarkNibbleTable4(b *testing.B) {
+	data := make([]byte, 1<<20)
+	if _, err := rand.Read(data); err != nil {
+		panic(err)
+	}
+	table := nibs.NewDecodeTable(4)
+	count := len(data) * 2
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		nib := nibs.New(bytes.NewReader(data))
+		for j := 0; j < count; j++ {
+			if _, err := nib.NibbleTable(table); err != nil {
+				b.Fatalf("unexpected error: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package nibs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"unicode"
+)
+
+// ErrInvalidTextBit is the error used when a text encoded bit stream read by a
+// Nibs created with `NewText` contains a character other than '0', '1' or
+// whitespace.
+var ErrInvalidTextBit = newSentinel("invalid text bit")
+
+// NewText returns a new Nibs which reads from the specified io.Reader
+// containing a bit stream written as UTF-8 text, with each '0' or '1'
+// character holding one bit. Whitespace is ignored, so "1011 0010" reads as
+// the byte 0xB2. The number of bits need not be a multiple of 8.
+//
+// Reading past any other character returns an error wrapping
+// ErrInvalidTextBit. The bits in whole bytes before the character can still
+// be read.
+func NewText(r io.Reader, opts ...Option) *Nibs {
+	rr, ok := r.(io.RuneReader)
+	if !ok {
+		rr = bufio.NewReader(r)
+	}
+	return New(&textReader{r: rr}, opts...)
+}
+
+// eofBitLengther is implemented by readers that pack bits into bytes and so
+// know the exact number of bits in the stream once it ends. See BitLengther
+// for readers that know it up front.
+type eofBitLengther interface {
+	bitLen() int64
+}
+
+// textReader packs the bits written as '0' and '1' characters into bytes.
+type textReader struct {
+	r    io.RuneReader
+	bits int64 // number of bits read, including any in a final partial byte
+}
+
+func (t *textReader) Read(p []byte) (int, error) {
+	for i := range p {
+		var b byte
+		for k := 0; k < 8; {
+			c, _, err := t.r.ReadRune()
+			if err != nil {
+				if errors.Is(err, io.EOF) && k > 0 {
+					// final partial byte, zero padded
+					p[i] = b << uint(8-k)
+					t.bits += int64(k)
+					return i + 1, err
+				}
+				return i, err
+			}
+			switch {
+			case c == '0' || c == '1':
+				b = b<<1 | byte(c-'0')
+				k++
+			case unicode.IsSpace(c):
+			default:
+				return i, fmt.Errorf("%w %q after %d bits", ErrInvalidTextBit, c, t.bits+int64(k))
+			}
+		}
+		p[i] = b
+		t.bits += 8
+	}
+	return len(p), nil
+}
+
+func (t *textReader) bitLen() int64 {
+	return t.bits
+}
@@ -0,0 +1,75 @@
+package nibs_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestNewText(t *testing.T) {
+	nib := nibs.NewText(strings.NewReader("10110010"))
+	if v, err := nib.Nibble(8); err != nil || v != 0xB2 {
+		t.Errorf("expected 0xB2, got %#x and error `%v`", v, err)
+	}
+	if _, err := nib.Nibble(1); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestNewTextWhitespace(t *testing.T) {
+	text := "1011 0010\n\t1111\r\n0000 1 "
+	nib := nibs.NewText(strings.NewReader(text))
+
+	expected := []uint64{0xB2, 0xF0, 1}
+	sizes := []int{8, 8, 1}
+	for i, size := range sizes {
+		if v, err := nib.Nibble(size); err != nil || v != expected[i] {
+			t.Errorf("expected %#x, got %#x and error `%v`", expected[i], v, err)
+		}
+	}
+	if left, err := nib.BitsRemaining(); err != nil || left != 0 {
+		t.Errorf("expected 0 bits remaining, got %d and error `%v`", left, err)
+	}
+}
+
+func TestNewTextPartialByte(t *testing.T) {
+	// 13 bits; the padding in the final byte is not readable
+	nib := nibs.NewText(strings.NewReader("1111111111111"))
+	if _, err := nib.Nibble(16); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+	if left, err := nib.BitsRemaining(); err != nil || left != 13 {
+		t.Errorf("expected 13 bits remaining, got %d and error `%v`", left, err)
+	}
+	if v, err := nib.Nibble(13); err != nil || v != 0x1FFF {
+		t.Errorf("expected 0x1fff, got %#x and error `%v`", v, err)
+	}
+	if _, err := nib.Nibble(1); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+
+	// long text spanning several buffer refills
+	nib = nibs.NewText(strings.NewReader(strings.Repeat("10", 1001)))
+	for i := 0; i < 1001; i++ {
+		if v, err := nib.Nibble(2); err != nil || v != 2 {
+			t.Fatalf("expected 2 at pair %d, got %d and error `%v`", i, v, err)
+		}
+	}
+	if _, err := nib.Nibble(1); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestNewTextInvalid(t *testing.T) {
+	nib := nibs.NewText(strings.NewReader("10110010 1x"))
+	if v, err := nib.Nibble(8); err != nil || v != 0xB2 {
+		t.Errorf("expected 0xB2, got %#x and error `%v`", v, err)
+	}
+	_, err := nib.Nibble(1)
+	if !errors.Is(err, nibs.ErrInvalidTextBit) {
+		t.Errorf("expected `nibs.ErrInvalidTextBit`, got `%v`", err)
+	}
+}
@@ -0,0 +1,100 @@
+package nibs
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// DOSDateTimeError is the error returned when a packed MS-DOS date or time
+// contains a component that is out of range.
+type DOSDateTimeError struct {
+	Field string // name of the component, e.g. "month"
+	Value int    // the value as decoded
+}
+
+func (e *DOSDateTimeError) Error() string {
+	return fmt.Sprintf("invalid DOS date/time %s: %d", e.Field, e.Value)
+}
+
+// NibbleDOSDateTime reads a 32-bit packed MS-DOS timestamp, as used by ZIP
+// headers and FAT directory entries, and returns it as a time.Time in UTC.
+// See `NibbleDOSDateTimeIn` for details.
+func (n *Nibs) NibbleDOSDateTime() (time.Time, error) {
+	return n.NibbleDOSDateTimeIn(time.UTC)
+}
+
+// NibbleDOSDateTimeIn reads a 32-bit packed MS-DOS timestamp and returns it as
+// a time.Time in the specified location. A nil location means UTC.
+//
+// The 16-bit time is read first, holding the hour (5 bits), minute (6 bits)
+// and seconds divided by two (5 bits), followed by the 16-bit date holding
+// the years since 1980 (7 bits), month (4 bits) and day (5 bits). Years 1980
+// to 2107 can be represented. The stream need not be byte aligned.
+//
+// A component out of range, such as month 13 or February 29th in a
+// non-leap year, results in a *DOSDateTimeError.
+func (n *Nibs) NibbleDOSDateTimeIn(loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	v, err := n.Nibble(32)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	hour := int(v >> 27 & 0x1F)
+	minute := int(v >> 21 & 0x3F)
+	second := int(v>>16&0x1F) * 2
+	year := int(v>>9&0x7F) + 1980
+	month := int(v >> 5 & 0x0F)
+	day := int(v & 0x1F)
+
+	switch {
+	case hour > 23:
+		return time.Time{}, n.wrap(&DOSDateTimeError{Field: "hour", Value: hour})
+	case minute > 59:
+		return time.Time{}, n.wrap(&DOSDateTimeError{Field: "minute", Value: minute})
+	case second > 59:
+		return time.Time{}, n.wrap(&DOSDateTimeError{Field: "second", Value: second})
+	case month < 1 || month > 12:
+		return time.Time{}, n.wrap(&DOSDateTimeError{Field: "month", Value: month})
+	case day < 1 || day > daysIn(time.Month(month), year):
+		return time.Time{}, n.wrap(&DOSDateTimeError{Field: "day", Value: day})
+	}
+	return time.Date(year, time.Month(month), day, hour, minute, second, 0, loc), nil
+}
+
+// NibbleDuration reads `bits` number of bits from the byte stream as an
+// unsigned count of `unit` and returns the product as a time.Duration. For
+// example, a 20-bit count of milliseconds is read with
+// `NibbleDuration(20, time.Millisecond)`.
+//
+// `bits` must be in the range 1 to 64 inclusive and `unit` must be positive,
+// otherwise nibs.ErrNibbleSize is returned. ErrOverflow is returned if the
+// duration exceeds the largest time.Duration, about 292 years.
+//
+// See `Nibble` method for details.
+func (n *Nibs) NibbleDuration(bits int, unit time.Duration) (time.Duration, error) {
+	if bits < 1 || bits > 64 {
+		return 0, n.sizeError("NibbleDuration", bits, 64)
+	}
+	if unit <= 0 {
+		return 0, n.misuseError("NibbleDuration", fmt.Sprintf("a unit of %v; must be positive", unit))
+	}
+
+	count, err := n.Nibble(bits)
+	if err != nil {
+		return 0, err
+	}
+	if count > uint64(math.MaxInt64/unit) {
+		return 0, n.wrap(ErrOverflow)
+	}
+	return time.Duration(count) * unit, nil
+}
+
+// daysIn returns the number of days in the month of the year.
+func daysIn(month time.Month, year int) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
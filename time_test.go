@@ -0,0 +1,129 @@
+package nibs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	. "github.com/wiggin77/nibs/_test"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestNibbleDOSDateTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		packed   []byte
+		expected time.Time
+	}{
+		{name: "epoch", packed: []byte{0x00, 0x00, 0x00, 0x21},
+			expected: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "leap day", packed: []byte{0x60, 0x00, 0x28, 0x5D},
+			expected: time.Date(2000, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{name: "rollover", packed: []byte{0xBF, 0x7D, 0xFF, 0x9F},
+			expected: time.Date(2107, 12, 31, 23, 59, 58, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		nib := nibs.New(bytes.NewReader(tt.packed))
+		tm, err := nib.NibbleDOSDateTime()
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", tt.name, err)
+			continue
+		}
+		if !tm.Equal(tt.expected) {
+			t.Errorf("expected %v for %s, got %v", tt.expected, tt.name, tm)
+		}
+	}
+}
+
+func TestNibbleDOSDateTimeIn(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+
+	// unaligned by a 3-bit prefix
+	nib := nibs.New(bytes.NewReader(PackBits("101 0110000000000000 0010100001011101")))
+	if _, err := nib.Nibble(3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tm, err := nib.NibbleDOSDateTimeIn(loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := time.Date(2000, 2, 29, 12, 0, 0, 0, loc)
+	if !tm.Equal(expected) || tm.Location() != loc {
+		t.Errorf("expected %v, got %v", expected, tm)
+	}
+}
+
+func TestNibbleDOSDateTimeInvalid(t *testing.T) {
+	tests := []struct {
+		packed []byte
+		field  string
+		value  int
+	}{
+		{packed: []byte{0xC0, 0x00, 0x00, 0x21}, field: "hour", value: 24},
+		{packed: []byte{0x07, 0x80, 0x00, 0x21}, field: "minute", value: 60},
+		{packed: []byte{0x00, 0x1E, 0x00, 0x21}, field: "second", value: 60},
+		{packed: []byte{0x00, 0x00, 0x00, 0x01}, field: "month", value: 0},
+		{packed: []byte{0x00, 0x00, 0x01, 0xA1}, field: "month", value: 13},
+		{packed: []byte{0x00, 0x00, 0x00, 0x20}, field: "day", value: 0},
+		{packed: []byte{0x00, 0x00, 0x2A, 0x5D}, field: "day", value: 29}, // 2001-02-29
+	}
+
+	for _, tt := range tests {
+		nib := nibs.New(bytes.NewReader(tt.packed))
+		_, err := nib.NibbleDOSDateTime()
+		var dtErr *nibs.DOSDateTimeError
+		if !errors.As(err, &dtErr) {
+			t.Errorf("expected *nibs.DOSDateTimeError for %x, got `%v`", tt.packed, err)
+			continue
+		}
+		if dtErr.Field != tt.field || dtErr.Value != tt.value {
+			t.Errorf("expected %s %d, got %s %d", tt.field, tt.value, dtErr.Field, dtErr.Value)
+		}
+	}
+}
+
+func TestNibbleDuration(t *testing.T) {
+	// 24-bit millisecond count of 0x123456 = 1193046ms, then 4 bits of 10 seconds
+	nib := nibs.New(bytes.NewReader([]byte{0x12, 0x34, 0x56, 0xA0}))
+	d, err := nib.NibbleDuration(24, time.Millisecond)
+	if err != nil || d != 1193046*time.Millisecond {
+		t.Errorf("expected 19m53.046s, got %v and error `%v`", d, err)
+	}
+	d, err = nib.NibbleDuration(4, 10*time.Second)
+	if err != nil || d != 100*time.Second {
+		t.Errorf("expected 1m40s, got %v and error `%v`", d, err)
+	}
+	if _, err := nib.NibbleDuration(8, time.Second); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestNibbleDurationInvalid(t *testing.T) {
+	b := bytes.Repeat([]byte{0xFF}, 24)
+	nib := nibs.New(bytes.NewReader(b))
+
+	if _, err := nib.NibbleDuration(0, time.Second); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, err := nib.NibbleDuration(65, time.Second); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, err := nib.NibbleDuration(8, 0); err == nil {
+		t.Error("expected error for zero unit")
+	}
+	// 63 bits of nanoseconds is the largest duration
+	if d, err := nib.NibbleDuration(63, time.Nanosecond); err != nil || d != math.MaxInt64 {
+		t.Errorf("expected max duration, got %v and error `%v`", d, err)
+	}
+	if _, err := nib.NibbleDuration(64, time.Nanosecond); err != nibs.ErrOverflow {
+		t.Errorf("expected `nibs.ErrOverflow`, got `%v`", err)
+	}
+	if _, err := nib.NibbleDuration(40, time.Hour); err != nibs.ErrOverflow {
+		t.Errorf("expected `nibs.ErrOverflow`, got `%v`", err)
+	}
+}
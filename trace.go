@@ -0,0 +1,31 @@
+package nibs
+
+// TracedNibble is a value read from the stream, as recorded by the option
+// `WithValueTrace`.
+type TracedNibble struct {
+	Value uint64 // value returned
+	Bits  int    // number of bits read
+}
+
+// WithValueTrace returns an option that records every value successfully
+// returned by `Nibble`, in order, for retrieval with `Trace`. Values read by
+// the other read methods are recorded as the nibbles they are made of, so a
+// `NibbleFibonacci` appears as a series of 1-bit reads. Bits passed over by
+// `Skip` or `AlignTo` are not recorded.
+//
+// The trace grows with every read, so the option is best kept for debugging
+// and tests.
+func WithValueTrace() Option {
+	return func(n *Nibs) {
+		n.tracing = true
+	}
+}
+
+// Trace returns a copy of the values recorded so far when the option
+// `WithValueTrace` is used. It returns nil if none have been recorded.
+func (n *Nibs) Trace() []TracedNibble {
+	if len(n.trace) == 0 {
+		return nil
+	}
+	return append([]TracedNibble(nil), n.trace...)
+}
@@ -0,0 +1,89 @@
+package nibs_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestWithValueTrace(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xAB, 0xCD, 0xEF, 0x12}), nibs.WithValueTrace())
+
+	nib.Nibble(4)
+	nib.Nibble8(8)
+	nib.Skip(4)
+	nib.Nibble16(12)
+	nib.Nibble(8)  // EOF, not recorded
+	nib.Nibble(65) // invalid, not recorded
+	nib.Nibble(4)
+
+	expected := []nibs.TracedNibble{
+		{Value: 0xA, Bits: 4},
+		{Value: 0xBC, Bits: 8},
+		{Value: 0xEF1, Bits: 12},
+		{Value: 0x2, Bits: 4},
+	}
+	trace := nib.Trace()
+	if len(trace) != len(expected) {
+		t.Fatalf("expected %d traced nibbles, got %d: %v", len(expected), len(trace), trace)
+	}
+	for i := range expected {
+		if trace[i] != expected[i] {
+			t.Errorf("trace %d: expected %+v, got %+v", i, expected[i], trace[i])
+		}
+	}
+
+	// the trace returned is a copy
+	trace[0].Value = 0
+	if nib.Trace()[0].Value != 0xA {
+		t.Error("expected Trace to return a copy")
+	}
+	if _, err := nib.Nibble(1); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestWithValueTraceComposite(t *testing.T) {
+	// Fibonacci code for 4 is 1011
+	nib := nibs.New(bytes.NewReader([]byte{0xB0}), nibs.WithValueTrace())
+	if v, err := nib.NibbleFibonacci(); err != nil || v != 4 {
+		t.Fatalf("expected 4, got %d and error `%v`", v, err)
+	}
+	trace := nib.Trace()
+	if len(trace) != 4 {
+		t.Fatalf("expected 4 traced nibbles, got %v", trace)
+	}
+	for i, bit := range []uint64{1, 0, 1, 1} {
+		if trace[i] != (nibs.TracedNibble{Value: bit, Bits: 1}) {
+			t.Errorf("trace %d: expected bit %d, got %+v", i, bit, trace[i])
+		}
+	}
+}
+
+func TestWithValueTraceSlice(t *testing.T) {
+	// values read several at a time are traced one by one
+	nib := nibs.New(bytes.NewReader([]byte{0x1B, 0xE4}), nibs.WithValueTrace())
+	vals, err := nib.NibbleTriStateSlice(8)
+	if err != nil || len(vals) != 8 {
+		t.Fatalf("expected 8 values, got %v and error `%v`", vals, err)
+	}
+	trace := nib.Trace()
+	if len(trace) != 8 {
+		t.Fatalf("expected 8 traced nibbles, got %v", trace)
+	}
+	for i, v := range []uint64{0, 1, 2, 3, 3, 2, 1, 0} {
+		if trace[i] != (nibs.TracedNibble{Value: v, Bits: 2}) {
+			t.Errorf("trace %d: expected %d, got %+v", i, v, trace[i])
+		}
+	}
+}
+
+func TestTraceDisabled(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0xAB}))
+	nib.Nibble(8)
+	if trace := nib.Trace(); trace != nil {
+		t.Errorf("expected nil trace without option, got %v", trace)
+	}
+}
@@ -0,0 +1,142 @@
+package nibs
+
+import (
+	"fmt"
+	"io"
+)
+
+// ErrLengthMismatch is the error used when the number of bits read does not
+// match a length declared by the stream.
+var ErrLengthMismatch = newSentinel("length mismatch")
+
+// ErrValidationFailed is the error used when a value read from the stream is
+// rejected by a caller supplied check.
+var ErrValidationFailed = newSentinel("validation failed")
+
+// ErrNotMonotonic is the error used when a value read from the stream is less
+// than the value read before it.
+var ErrNotMonotonic = newSentinel("value not monotonic")
+
+// ErrIntegrityMismatch is the error used when a redundant field read from the
+// stream does not equal the value computed for it.
+var ErrIntegrityMismatch = newSentinel("integrity mismatch")
+
+// VerifyLength compares the number of bits read so far, as returned by
+// `BitsRead`, with `declaredBits`, which is typically the total length
+// declared in a header. Call it once parsing is complete to catch both
+// truncated streams and trailing garbage.
+//
+// An error wrapping ErrLengthMismatch, and stating both lengths, is
+// returned if they differ.
+func (n *Nibs) VerifyLength(declaredBits int64) error {
+	if read := n.BitsRead(); read != declaredBits {
+		return n.wrap(fmt.Errorf("%w: declared %d bits, read %d", ErrLengthMismatch, declaredBits, read))
+	}
+	return nil
+}
+
+// NibbleMonotonic reads `bits` number of bits from the byte stream, like
+// `Nibble`, and checks that the value is not less than the value returned by
+// the previous call, as with timestamps or sequence numbers. An error wrapping
+// ErrNotMonotonic, and stating both values, is returned for a smaller value.
+// The bits are consumed in that case and the previous value is kept. The first
+// call, and the first after `Reset` or `Rewind`, accepts any value.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+func (n *Nibs) NibbleMonotonic(bits int) (uint64, error) {
+	v, err := n.Nibble(bits)
+	if err != nil {
+		return 0, err
+	}
+	if n.monotonicSeen && v < n.monotonic {
+		return 0, n.wrap(fmt.Errorf("%w: %d follows %d", ErrNotMonotonic, v, n.monotonic))
+	}
+	n.monotonic, n.monotonicSeen = v, true
+	return v, nil
+}
+
+// NibbleValidated reads `bits` number of bits from the byte stream, like
+// `Nibble`, and passes the value to `valid`. An error wrapping
+// ErrValidationFailed, and stating the value, is returned if `valid` returns
+// false. The bits are consumed in that case.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+func (n *Nibs) NibbleValidated(bits int, valid func(uint64) bool) (uint64, error) {
+	v, err := n.Nibble(bits)
+	if err != nil {
+		return 0, err
+	}
+	if !valid(v) {
+		return 0, n.wrap(fmt.Errorf("%w: value %d", ErrValidationFailed, v))
+	}
+	return v, nil
+}
+
+// NibbleExpectFunc reads `bits` number of bits from the byte stream, like
+// `Nibble`, and checks that the value equals the one returned by `expect`, for
+// redundant fields such as a total that must equal the sum of the fields
+// before it. `expect` is called after the read succeeds. An error wrapping
+// ErrIntegrityMismatch, and stating both values, is returned if they differ.
+// The bits are consumed in that case.
+//
+// `bits` must be in the range 1 to 64 inclusive, otherwise
+// nibs.ErrNibbleSize is returned.
+func (n *Nibs) NibbleExpectFunc(bits int, expect func() uint64) error {
+	v, err := n.Nibble(bits)
+	if err != nil {
+		return err
+	}
+	if want := expect(); v != want {
+		return n.wrap(fmt.Errorf("%w: expected %d, read %d", ErrIntegrityMismatch, want, v))
+	}
+	return nil
+}
+
+// FieldSpec describes a fixed width field for `Validate`.
+type FieldSpec struct {
+	Name string // name of the field, as for `Enter`
+	Bits int    // width of the field, 1 to 64 bits
+}
+
+// Validate reads `fields` in order from `r` and returns nil only if every
+// field is present and the stream ends with the last of them, for checking
+// that a blob is well formed without extracting its values. Up to 7 bits of
+// zero padding may follow the last field to complete its byte.
+//
+// An error for a field holds its name, as for `Enter`. The stream ending
+// before the last field is complete returns an error wrapping
+// io.ErrUnexpectedEOF, and a width out of range one wrapping
+// nibs.ErrNibbleSize. An error wrapping ErrLengthMismatch is returned if
+// another byte or more follows the last field, and one wrapping
+// ErrNonZeroPadding if the padding has any bits set.
+func Validate(r io.Reader, fields []FieldSpec) error {
+	n := New(r)
+	for _, f := range fields {
+		n.Enter(f.Name)
+		_, err := n.nibble(f.Bits)
+		if err != nil {
+			return unexpected(err)
+		}
+		n.Leave()
+	}
+
+	left, err := n.MaxReadableNow()
+	if err != nil {
+		return err
+	}
+	if left >= 8 {
+		return n.wrap(fmt.Errorf("%w: data follows the last field", ErrLengthMismatch))
+	}
+	if left > 0 {
+		v, err := n.nibble(left)
+		if err != nil {
+			return err
+		}
+		if v != 0 {
+			return n.wrap(fmt.Errorf("%w: %#x after the last field", ErrNonZeroPadding, v))
+		}
+	}
+	return nil
+}
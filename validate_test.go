@@ -0,0 +1,153 @@
+package nibs_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestVerifyLength(t *testing.T) {
+	const size = 200
+	nib := nibs.New(bytes.NewReader(make([]byte, size)))
+
+	// read it all in odd sized pieces
+	for {
+		if _, err := nib.Nibble(13); err != nil {
+			break
+		}
+	}
+	r, err := nib.BitsRemaining()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := nib.Nibble(r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if read := nib.BitsRead(); read != size*8 {
+		t.Errorf("expected %d bits read, got %d", size*8, read)
+	}
+	if err := nib.VerifyLength(size * 8); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err = nib.VerifyLength(size*8 + 16)
+	if !errors.Is(err, nibs.ErrLengthMismatch) {
+		t.Errorf("expected error wrapping `nibs.ErrLengthMismatch`, got `%v`", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "declared 1616 bits, read 1600") {
+		t.Errorf("expected both lengths in error, got `%v`", err)
+	}
+}
+
+func TestNibbleMonotonic(t *testing.T) {
+	// 8-bit values 3, 3, 7, 200, 199, 201
+	b := []byte{3, 3, 7, 200, 199, 201}
+	nib := nibs.New(bytes.NewReader(b))
+
+	for _, expected := range b[:4] {
+		if v, err := nib.NibbleMonotonic(8); err != nil || v != uint64(expected) {
+			t.Errorf("expected %d, got %d and error `%v`", expected, v, err)
+		}
+	}
+	if v, err := nib.NibbleMonotonic(8); !errors.Is(err, nibs.ErrNotMonotonic) || v != 0 {
+		t.Errorf("expected `nibs.ErrNotMonotonic` for 199, got %d and error `%v`", v, err)
+	}
+	// compared with 200, the last value accepted
+	if v, err := nib.NibbleMonotonic(8); err != nil || v != 201 {
+		t.Errorf("expected 201, got %d and error `%v`", v, err)
+	}
+
+	// Reset forgets the previous value
+	nib.Reset(bytes.NewReader([]byte{1, 0}))
+	if v, err := nib.NibbleMonotonic(8); err != nil || v != 1 {
+		t.Errorf("expected 1 after Reset, got %d and error `%v`", v, err)
+	}
+	if _, err := nib.NibbleMonotonic(8); !errors.Is(err, nibs.ErrNotMonotonic) {
+		t.Errorf("expected `nibs.ErrNotMonotonic` for 0, got `%v`", err)
+	}
+}
+
+func TestNibbleValidated(t *testing.T) {
+	even := func(v uint64) bool { return v%2 == 0 }
+	nib := nibs.New(bytes.NewReader([]byte{0x8A, 0x3F}))
+
+	if v, err := nib.NibbleValidated(4, even); err != nil || v != 8 {
+		t.Errorf("expected 8, got %d and error `%v`", v, err)
+	}
+	if v, err := nib.NibbleValidated(4, even); err != nil || v != 10 {
+		t.Errorf("expected 10, got %d and error `%v`", v, err)
+	}
+
+	nib.Enter("count")
+	v, err := nib.NibbleValidated(8, even)
+	if !errors.Is(err, nibs.ErrValidationFailed) || v != 0 {
+		t.Errorf("expected `nibs.ErrValidationFailed`, got %d and error `%v`", v, err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "count") || !strings.Contains(err.Error(), "63") {
+		t.Errorf("expected error stating the field and value, got `%v`", err)
+	}
+	nib.Leave()
+
+	if _, err := nib.NibbleValidated(8, even); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+}
+
+func TestNibbleExpectFunc(t *testing.T) {
+	// three 4-bit counts followed by their 8-bit total, twice; the second
+	// total is wrong
+	nib := nibs.New(bytes.NewReader([]byte{0x12, 0x30, 0x61, 0x11, 0x04}))
+
+	for i, expected := range []error{nil, nibs.ErrIntegrityMismatch} {
+		var sum uint64
+		for j := 0; j < 3; j++ {
+			v, err := nib.Nibble(4)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			sum += v
+		}
+		err := nib.NibbleExpectFunc(8, func() uint64 { return sum })
+		if !errors.Is(err, expected) || (expected == nil) != (err == nil) {
+			t.Errorf("record %d: expected `%v`, got `%v`", i, expected, err)
+		}
+	}
+
+	called := false
+	if err := nib.NibbleExpectFunc(8, func() uint64 { called = true; return 0 }); err != io.EOF || called {
+		t.Errorf("expected `io.EOF` without calling expect, got `%v`", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	// a 4-bit version, 12-bit length and 5-bit flags, padded to 3 bytes
+	spec := []nibs.FieldSpec{
+		{Name: "version", Bits: 4},
+		{Name: "length", Bits: 12},
+		{Name: "flags", Bits: 5},
+	}
+
+	if err := nibs.Validate(bytes.NewReader([]byte{0x12, 0x34, 0xA8}), spec); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err := nibs.Validate(bytes.NewReader([]byte{0x12, 0x34}), spec)
+	if !errors.Is(err, io.ErrUnexpectedEOF) || !strings.Contains(err.Error(), "flags") {
+		t.Errorf("expected `io.ErrUnexpectedEOF` in flags, got `%v`", err)
+	}
+	if err := nibs.Validate(bytes.NewReader([]byte{0x12, 0x34, 0xA8, 0x00}), spec); !errors.Is(err, nibs.ErrLengthMismatch) {
+		t.Errorf("expected `nibs.ErrLengthMismatch`, got `%v`", err)
+	}
+	if err := nibs.Validate(bytes.NewReader([]byte{0x12, 0x34, 0xA9}), spec); !errors.Is(err, nibs.ErrNonZeroPadding) {
+		t.Errorf("expected `nibs.ErrNonZeroPadding`, got `%v`", err)
+	}
+	bad := []nibs.FieldSpec{{Name: "huge", Bits: 65}}
+	if err := nibs.Validate(bytes.NewReader(make([]byte, 16)), bad); !errors.Is(err, nibs.ErrNibbleSize) {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+}
@@ -0,0 +1,28 @@
+package nibs
+
+import "fmt"
+
+// NibbleVersion reads a version packed as three consecutive fields holding
+// the major, minor and patch numbers, of `majorBits`, `minorBits` and
+// `patchBits` bits respectively. For example, a 16-bit version word with a
+// 4-bit major, 8-bit minor and 4-bit patch is read with
+// `NibbleVersion(4, 8, 4)`.
+//
+// Each width must be at least 1 and the widths must sum to at most 64,
+// otherwise nibs.ErrNibbleSize is returned. The fields are read together, so
+// no bits are consumed if the stream ends part way through the version.
+//
+// See `Nibble` method for details.
+func (n *Nibs) NibbleVersion(majorBits, minorBits, patchBits int) (major, minor, patch uint64, err error) {
+	if majorBits < 1 || minorBits < 1 || patchBits < 1 {
+		return 0, 0, 0, n.misuseError("NibbleVersion", fmt.Sprintf("%d, %d and %d bits; each must be at least 1", majorBits, minorBits, patchBits))
+	}
+	v, err := n.Nibble(majorBits + minorBits + patchBits)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major = v >> uint(minorBits+patchBits)
+	minor = v >> uint(patchBits) & (1<<uint(minorBits) - 1)
+	patch = v & (1<<uint(patchBits) - 1)
+	return major, minor, patch, nil
+}
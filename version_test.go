@@ -0,0 +1,42 @@
+package nibs_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/wiggin77/nibs"
+)
+
+func TestNibbleVersion(t *testing.T) {
+	// 4/8/4 bits: 0001 00010100 0011 = 1.20.3
+	nib := nibs.New(bytes.NewReader([]byte{0x11, 0x43, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}))
+	major, minor, patch, err := nib.NibbleVersion(4, 8, 4)
+	if err != nil || major != 1 || minor != 20 || patch != 3 {
+		t.Errorf("expected 1.20.3, got %d.%d.%d and error `%v`", major, minor, patch, err)
+	}
+
+	// widths summing to exactly 64
+	major, minor, patch, err = nib.NibbleVersion(32, 16, 16)
+	if err != nil || major != 1<<32-1 || minor != 1<<16-1 || patch != 1<<16-1 {
+		t.Errorf("expected all ones, got %d.%d.%d and error `%v`", major, minor, patch, err)
+	}
+}
+
+func TestNibbleVersionInvalid(t *testing.T) {
+	nib := nibs.New(bytes.NewReader([]byte{0x11, 0x43}))
+	if _, _, _, err := nib.NibbleVersion(32, 32, 1); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+	if _, _, _, err := nib.NibbleVersion(4, 0, 4); err != nibs.ErrNibbleSize {
+		t.Errorf("expected `nibs.ErrNibbleSize`, got `%v`", err)
+	}
+
+	// truncated version consumes nothing
+	if _, _, _, err := nib.NibbleVersion(8, 8, 8); err != io.EOF {
+		t.Errorf("expected `io.EOF`, got `%v`", err)
+	}
+	if v, err := nib.Nibble(16); err != nil || v != 0x1143 {
+		t.Errorf("expected 0x1143, got %#x and error `%v`", v, err)
+	}
+}